@@ -0,0 +1,176 @@
+// Command otelstarter provides tooling for the telemetry written by otel go starter.
+//
+//	otelstarter replay [flags] FILE...
+//
+// replay sends the OTLP/JSON lines written by the file exporters to the exporter configured
+// by the flags, an optional configuration file and the OTEL_EXPORTER_OTLP_* environment variables,
+// using the tracing export for spans and the metrics export for metrics.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/metric"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/trace"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/replay"
+)
+
+func main() {
+	ctx, done := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer done()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// errUsage is returned once the usage has been written for invalid arguments
+var errUsage = errors.New("invalid usage")
+
+// run returns the exit code, 2 for invalid arguments and 1 when replaying fails
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "replay" {
+		fmt.Fprintln(stderr, "usage: otelstarter replay [flags] FILE...")
+		return 2
+	}
+	if err := runReplay(ctx, args[1:], stdout, stderr); err != nil {
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		fmt.Fprintln(stderr, "replay:", err)
+		return 1
+	}
+	return 0
+}
+
+// headers collects the repeated -header key=value flags
+type headers map[string]string
+
+func (h headers) String() string {
+	pairs := make([]string, 0, len(h))
+	for k, v := range h {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (h headers) Set(v string) error {
+	kv := strings.SplitN(v, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("header %q must be key=value", v)
+	}
+	h[kv[0]] = kv[1]
+	return nil
+}
+
+func runReplay(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	var (
+		fs          = flag.NewFlagSet("replay", flag.ContinueOnError)
+		configPath  = fs.String("config", "", "configuration `file` defining the tracing and metrics exporters")
+		exporter    = fs.String("exporter", "", "exporter used to send the telemetry, one of otlpgrpc, otlphttp or file")
+		endpoint    = fs.String("endpoint", "", "endpoint the telemetry is sent to")
+		insecure    = fs.Bool("insecure", false, "connect to the endpoint without TLS")
+		compression = fs.Bool("compression", false, "compress the requests using gzip")
+		caFile      = fs.String("ca-file", "", "certificate authorities used to verify the endpoint")
+		timeout     = fs.Duration("timeout", 0, "limit on how long each request is able to take")
+		path        = fs.String("path", "", "file written to when using the file exporter")
+		rate        = fs.Float64("rate", 0, "maximum number of lines sent each second, 0 is unlimited")
+		checkpoint  = fs.String("checkpoint", "", "`file` recording the progress through each file so replays can be resumed")
+		dryRun      = fs.Bool("dry-run", false, "validate the files without sending them")
+		hdrs        = headers{}
+	)
+	fs.Var(hdrs, "header", "`key=value` header sent with each request, may be repeated")
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: otelstarter replay [flags] FILE...")
+		fs.PrintDefaults()
+	}
+	// The flag set writes the parse error and usage to stderr
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	var exportOpts []config.ExportOption
+	if *exporter != "" {
+		exportOpts = append(exportOpts, config.WithExporterNamed(*exporter))
+	}
+	if *endpoint != "" {
+		exportOpts = append(exportOpts, config.WithExporterEndpoint(*endpoint))
+	}
+	if *insecure {
+		exportOpts = append(exportOpts, config.WithExporterInsecureConnection())
+	}
+	if *compression {
+		exportOpts = append(exportOpts, config.WithExporterUseCompression())
+	}
+	if *caFile != "" {
+		exportOpts = append(exportOpts, config.WithExporterCACertificate(*caFile))
+	}
+	if *timeout != 0 {
+		exportOpts = append(exportOpts, config.WithExporterTimeout(*timeout))
+	}
+	if *path != "" {
+		exportOpts = append(exportOpts, config.WithExporterPath(*path))
+	}
+	if len(hdrs) != 0 {
+		exportOpts = append(exportOpts, config.WithExporterHeaders(hdrs))
+	}
+
+	opts := []config.OptionFunc{config.FromEnvironment()}
+	if *configPath != "" {
+		opts = append(opts, config.FromFile(*configPath))
+	} else {
+		// Without a configuration file the flags define the exporter of both pipelines
+		opts = append(opts, config.WithTracesPipeline(), config.WithMetricsPipeline())
+	}
+	// The flags are applied without enabling the pipelines the configuration file leaves disabled
+	opts = append(opts, func(c *config.Config) error {
+		return multierr.Append(
+			config.WithTracingExporterOptions(exportOpts...)(&c.Tracing),
+			config.WithMetricsExporterOptions(exportOpts...)(&c.Metrics),
+		)
+	})
+	c := config.NewDefault()
+	if err := c.Apply(opts...); err != nil {
+		return err
+	}
+
+	// A signal without an enabled pipeline has no client, so its lines are reported by Replay
+	ropts := replay.Options{Rate: *rate, Checkpoint: *checkpoint, DryRun: *dryRun}
+	if !*dryRun {
+		var err error
+		if c.Tracing.Enable {
+			if ropts.Traces, err = trace.NewClient(&c.Tracing.Export); err != nil {
+				return err
+			}
+		}
+		if c.Metrics.Enable {
+			if ropts.Metrics, err = metric.NewClient(&c.Metrics.Export); err != nil {
+				return err
+			}
+		}
+	}
+
+	start := time.Now()
+	summary, err := replay.Replay(ctx, ropts, fs.Args()...)
+	verb := "Sent"
+	if *dryRun {
+		verb = "Validated"
+	}
+	fmt.Fprintf(stdout, "%s %d lines containing %d spans and %d metrics in %s\n",
+		verb, summary.Lines, summary.Spans, summary.Metrics, time.Since(start).Round(time.Millisecond))
+	return err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MovieStoreGuy/otel-go-starter/otelstartertest/collector"
+)
+
+const (
+	tracesLine  = `{"resourceSpans":[{"instrumentationLibrarySpans":[{"spans":[{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","spanId":"00f067aa0ba902b7","name":"checkout"}]}]}]}`
+	metricsLine = `{"resourceMetrics":[{"instrumentationLibraryMetrics":[{"metrics":[{"name":"orders","sum":{"dataPoints":[{"asInt":"3"}],"aggregationTemporality":"AGGREGATION_TEMPORALITY_CUMULATIVE","isMonotonic":true}}]}]}]}`
+)
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestRunUsage(t *testing.T) {
+	t.Parallel()
+
+	valid := writeFile(t, tracesLine+"\n")
+	for _, tc := range []struct {
+		scenario string
+		args     []string
+		stderr   string
+	}{
+		{scenario: "no command", args: nil, stderr: "usage: otelstarter replay"},
+		{scenario: "unknown command", args: []string{"upload"}, stderr: "usage: otelstarter replay"},
+		{scenario: "no files", args: []string{"replay", "-dry-run"}, stderr: "-checkpoint file"},
+		{scenario: "help", args: []string{"replay", "-h"}, stderr: "-checkpoint file"},
+		{scenario: "unknown flag", args: []string{"replay", "-verbose", valid}, stderr: "flag provided but not defined: -verbose"},
+		{scenario: "invalid header", args: []string{"replay", "-header", "nokey", valid}, stderr: `header "nokey" must be key=value`},
+	} {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, 2, run(context.Background(), tc.args, &stdout, &stderr), "Must exit with 2 for invalid arguments")
+			assert.Contains(t, stderr.String(), tc.stderr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	t.Parallel()
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"replay", "-dry-run", writeFile(t, tracesLine+"\n\n"+tracesLine+"\n")}, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Contains(t, stdout.String(), "Validated 2 lines containing 2 spans and 0 metrics")
+	assert.Empty(t, stderr.String())
+}
+
+func TestRunFailure(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		scenario string
+		args     []string
+		stderr   string
+	}{
+		{scenario: "invalid line", args: []string{"replay", "-dry-run", writeFile(t, "{\n")}, stderr: "telemetry.jsonl:1:"},
+		{scenario: "missing file", args: []string{"replay", "-dry-run", filepath.Join(t.TempDir(), "missing.jsonl")}, stderr: "missing.jsonl"},
+		{scenario: "missing config", args: []string{"replay", "-config", filepath.Join(t.TempDir(), "missing.yaml"), writeFile(t, tracesLine)}, stderr: "missing.yaml"},
+		{scenario: "unsupported exporter", args: []string{"replay", "-exporter", "zipkin", writeFile(t, tracesLine)}, stderr: "replay:"},
+	} {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, 1, run(context.Background(), tc.args, &stdout, &stderr), "Must exit with 1 when replaying fails")
+			assert.Contains(t, stderr.String(), "replay:")
+			assert.Contains(t, stderr.String(), tc.stderr)
+		})
+	}
+}
+
+func TestRunReplay(t *testing.T) {
+	t.Parallel()
+
+	c := collector.New(t)
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{
+		"replay",
+		"-exporter", "otlphttp",
+		"-endpoint", c.HTTPEndpoint(),
+		"-insecure",
+		"-compression",
+		"-header", "x-tenant=movies",
+		writeFile(t, tracesLine+"\n"),
+	}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	assert.Contains(t, stdout.String(), "Sent 1 lines containing 1 spans and 0 metrics")
+
+	requests := c.Requests()
+	require.Len(t, requests, 1, "Must send the line to the configured endpoint")
+	assert.Equal(t, collector.ProtocolHTTP, requests[0].Protocol)
+	assert.Equal(t, "movies", requests[0].Headers.Get("x-tenant"), "Must send the configured headers")
+	assert.Equal(t, "gzip", requests[0].Compression)
+}
+
+func TestRunReplayEnabledPipelines(t *testing.T) {
+	t.Parallel()
+
+	c := collector.New(t)
+	conf := filepath.Join(t.TempDir(), "otel.yaml")
+	require.NoError(t, os.WriteFile(conf, []byte("tracing:\n  enabled: true\n"), 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{
+		"replay",
+		"-config", conf,
+		"-exporter", "otlphttp",
+		"-endpoint", c.HTTPEndpoint(),
+		"-insecure",
+		writeFile(t, tracesLine+"\n"+metricsLine+"\n"),
+	}, &stdout, &stderr)
+	assert.Equal(t, 1, code, "Must fail to replay the metrics without a metrics pipeline")
+	assert.Contains(t, stderr.String(), "no client configured")
+	assert.Contains(t, stdout.String(), "Sent 1 lines containing 1 spans and 0 metrics")
+	assert.Len(t, c.Requests(), 1, "Must send the lines of the enabled pipeline")
+}
@@ -2,11 +2,20 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
 )
 
 var (
@@ -17,9 +26,27 @@ var (
 type Config struct {
 	Metrics Metrics
 	Tracing Tracing
+	Logs    Logs
+
+	OnFailure FailurePolicy
+	// DisableGlobals stops the launcher from setting the global
+	// providers, propagator and error handler
+	DisableGlobals bool
+	// ResourceDetectors are the names of the built in detectors
+	// that are run when the launcher starts
+	ResourceDetectors []string
+	// ReportResourceConflicts sends the resource conflicts
+	// to the error handler when the launcher starts
+	ReportResourceConflicts bool
 
-	errHandler otel.ErrorHandler
-	resource   *resource.Resource
+	errHandler     otel.ErrorHandler
+	resources      []resourceEntry
+	detectorSource detectors.Source
+	// envErrs are the errors from reading the exporter environment
+	// variables that are reported by Apply once the pipeline is enabled
+	envErrs struct {
+		tracing, metrics, logs error
+	}
 }
 
 type Export struct {
@@ -28,23 +55,216 @@ type Export struct {
 	Named          string
 	Endpoint       string
 	Headers        map[string]string
+	// TLS defines the certificates used to connect to the Endpoint
+	TLS TLS
+	// Timeout limits how long each export request is able to take
+	Timeout time.Duration
+	// Retry overrides the OTLP exporter's retry settings when set
+	Retry *Retry
+	// Mux is used by the prometheus exporter to register its handler
+	// instead of serving the metrics on the Endpoint,
+	// it must not be reused once the exporter has been shutdown
+	Mux ServeMux
+	// Path is the file written to by the file exporter
+	Path string
+	// File controls the rotation and syncing of the file at Path
+	File File
+
+	// inherited tracks headers read from the environment
+	// so that they can be overridden by explicit options
+	inherited map[string]struct{}
+	// baseEndpoint is set when the Endpoint was read from OTEL_EXPORTER_OTLP_ENDPOINT,
+	// its path is then a prefix of each signal's path
+	baseEndpoint bool
+}
+
+// ServeMux allows an exporter's http.Handler to be mounted
+// on an existing server, ie *http.ServeMux
+type ServeMux interface {
+	Handle(pattern string, handler http.Handler)
 }
 
 type Tracing struct {
 	Enable bool
 
 	Export Export
+	// Exports are additional exporters that spans
+	// are sent to alongside the primary Export
+	Exports []Export
+
+	// Sample forces every span to be sampled when no Sampler name is set.
+	//
+	// Deprecated: use Sampler with the always_on sampler instead
+	Sample  bool
+	Sampler Sampler
+	// SamplingRules are evaluated in order before the Sampler for root spans,
+	// the first matching rule decides if the span is sampled
+	SamplingRules []SamplingRule
+	Propagators   []string
+
+	// Batch configures the batch span processor used for each exporter
+	Batch Batch
+	// Synchronous exports each span as it ends using a simple span processor
+	// instead of batching them, intended for short lived processes
+	Synchronous bool
+	// Limits restricts the size of each span
+	Limits Limits
+}
+
+// Limits restricts the number of attributes, events and links
+// recorded on each span, zero values use the SDK defaults
+type Limits struct {
+	AttributeCount int
+	// AttributeValueLength truncates string attribute values
+	// to the number of characters, zero does not truncate values
+	AttributeValueLength int
+	EventCount           int
+	LinkCount            int
+	// AttributePerEventCount and AttributePerLinkCount limit
+	// the attributes recorded on each event and link
+	AttributePerEventCount int
+	AttributePerLinkCount  int
+}
+
+// Batch configures the batch span processor and the batch log record processor,
+// zero values use the SDK defaults
+type Batch struct {
+	MaxQueueSize       int
+	MaxExportBatchSize int
+	// Timeout is the longest time spans or records are held before being exported
+	Timeout time.Duration
+	// ExportTimeout limits how long each export is able to take
+	ExportTimeout time.Duration
+	// BlockOnQueueFull waits for space in the queue
+	// instead of dropping spans or records when the queue is full
+	BlockOnQueueFull bool
+}
+
+// Sampler names the sampler used by the tracing pipeline,
+// the names match the values used by OTEL_TRACES_SAMPLER with the
+// addition of ratelimited and parentbased_ratelimited.
+// An empty name uses parentbased_always_on.
+type Sampler struct {
+	Name string
+	// Arg is the ratio of traces to sample for the traceidratio samplers,
+	// or the number of traces per second for the ratelimited samplers.
+	Arg float64
+}
+
+// SamplingRule matches root spans using all of the set conditions
+// and samples matching spans using the rule's Sampler, spans with a parent
+// are sampled by the tracing Sampler instead so that traces are not broken.
+// Conditions are evaluated against the values known when the span is started.
+type SamplingRule struct {
+	// SpanName is a glob pattern matched against the span name, see path.Match
+	SpanName string
+	// SpanKind is one of internal, server, client, producer or consumer
+	SpanKind string
+	// Attributes must be equal to the span's attribute values
+	Attributes map[string]string
+	// AttributePatterns are regular expressions matched against the span's attribute values
+	AttributePatterns map[string]string
+
+	Sampler Sampler
+}
 
-	Sample      bool
-	Propagators []string
+// spanKinds are the values of SamplingRule.SpanKind
+var spanKinds = map[string]trace.SpanKind{
+	"":         trace.SpanKindUnspecified,
+	"internal": trace.SpanKindInternal,
+	"server":   trace.SpanKindServer,
+	"client":   trace.SpanKindClient,
+	"producer": trace.SpanKindProducer,
+	"consumer": trace.SpanKindConsumer,
 }
 
+// Validate checks the rule's patterns, span kind and sampler,
+// setting the sampler's argument to zero when it does not use one
+func (r *SamplingRule) Validate() (err error) {
+	if _, err := path.Match(r.SpanName, ""); err != nil {
+		return fmt.Errorf("invalid span name pattern %q: %w", r.SpanName, ErrInvalidParam)
+	}
+	if _, ok := spanKinds[r.SpanKind]; !ok {
+		return fmt.Errorf("unknown span kind %q: %w", r.SpanKind, ErrInvalidParam)
+	}
+	for k, pattern := range r.AttributePatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid attribute pattern for %s: %v: %w", k, err, ErrInvalidParam)
+		}
+	}
+	r.Sampler, err = newSampler(r.Sampler.Name, r.Sampler.Arg)
+	return err
+}
+
+// Kind returns the span kind matched by the rule,
+// trace.SpanKindUnspecified matches every kind
+func (r SamplingRule) Kind() trace.SpanKind {
+	return spanKinds[r.SpanKind]
+}
+
+// Sampler names supported by the tracing pipeline
+const (
+	SamplerAlwaysOn                = "always_on"
+	SamplerAlwaysOff               = "always_off"
+	SamplerTraceIDRatio            = "traceidratio"
+	SamplerRateLimited             = "ratelimited"
+	SamplerParentBasedAlwaysOn     = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff    = "parentbased_always_off"
+	SamplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+	SamplerParentBasedRateLimited  = "parentbased_ratelimited"
+)
+
 type Metrics struct {
 	Enable bool
 
 	Export Export
+	// Exports are additional exporters that metrics
+	// are sent to alongside the primary Export
+	Exports []Export
 
+	// CollectPeriod is the interval between each collection and export
 	CollectPeriod time.Duration
+	// PushTimeout limits how long each export is able to take
+	PushTimeout time.Duration
+}
+
+type Logs struct {
+	Enable bool
+
+	Export Export
+	// Exports are additional exporters that log records
+	// are sent to alongside the primary Export
+	Exports []Export
+
+	// Batch configures the batch log record processor used for each exporter
+	Batch Batch
+}
+
+// FailurePolicy defines how the launcher reacts when
+// a pipeline is unable to be started
+type FailurePolicy int
+
+const (
+	// FailurePolicyAbort stops the launcher and returns the error to the caller
+	FailurePolicyAbort FailurePolicy = iota
+	// FailurePolicyNoop reports the error to the configured error handler
+	// and replaces the failed pipeline with a no-op implementation
+	FailurePolicyNoop
+)
+
+// AllExports returns the primary export followed by any additional exports
+func (t *Tracing) AllExports() []Export {
+	return append([]Export{t.Export}, t.Exports...)
+}
+
+// AllExports returns the primary export followed by any additional exports
+func (m *Metrics) AllExports() []Export {
+	return append([]Export{m.Export}, m.Exports...)
+}
+
+// AllExports returns the primary export followed by any additional exports
+func (l *Logs) AllExports() []Export {
+	return append([]Export{l.Export}, l.Exports...)
 }
 
 // Method types to programatically validate additions
@@ -54,32 +274,114 @@ type (
 	ExportOption  func(*Export) error
 	TracingOption func(*Tracing) error
 	MetricsOption func(*Metrics) error
+	LogsOption    func(*Logs) error
 )
 
+// Retry defines how OTLP exporters retry failed exports
+// using an exponential backoff between each attempt,
+// the otlphttp metrics exporter only uses InitialInterval
+type Retry struct {
+	Enabled bool
+	// InitialInterval is the time waited after the first failure
+	InitialInterval time.Duration
+	// MaxInterval is the upper bound of the time waited between each attempt
+	MaxInterval time.Duration
+	// MaxElapsedTime is the total time spent retrying before the data is dropped
+	MaxElapsedTime time.Duration
+}
+
+// File defines how the file exporter writes to its path
+type File struct {
+	// MaxSize is the size in bytes the file may reach before it is rotated,
+	// zero disables rotation
+	MaxSize int64
+	// MaxBackups limits the number of rotated files that are kept,
+	// zero keeps every rotated file
+	MaxBackups int
+	// SyncEachExport flushes the file to disk after each export
+	// rather than only when the exporter is shutdown
+	SyncEachExport bool
+}
+
+// HostPort returns the host and port of the Endpoint
+// as expected by the OTLP exporters
+func (e Export) HostPort() string {
+	u, err := url.Parse(e.Endpoint)
+	if err != nil || u.Host == "" {
+		return e.Endpoint
+	}
+	return u.Host
+}
+
+// URLPath returns the path the OTLP/HTTP exporters send the signal's data to,
+// one of traces, metrics or logs. An Endpoint without a path uses the exporter's
+// default path, otherwise the path is used as is unless the Endpoint was read from
+// OTEL_EXPORTER_OTLP_ENDPOINT where the signal's default path is appended to it.
+func (e Export) URLPath(signal string) string {
+	u, err := url.Parse(e.Endpoint)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	switch {
+	case path == "":
+		return ""
+	case e.baseEndpoint:
+		return path + "/v1/" + signal
+	}
+	return u.Path
+}
+
+// Insecure returns true when the exporter must not use TLS,
+// either from AllowInsecure or an http Endpoint
+func (e Export) Insecure() bool {
+	return e.AllowInsecure || strings.HasPrefix(e.Endpoint, "http://")
+}
+
+// NewDefaultRetry returns the retry settings used by the OTLP exporters
+// when none have been configured
+func NewDefaultRetry() Retry {
+	return Retry{
+		Enabled:         true,
+		InitialInterval: 5 * time.Second,
+		MaxInterval:     30 * time.Second,
+		MaxElapsedTime:  time.Minute,
+	}
+}
+
+// NewDefaultExport returns the exporter configuration
+// used by the pipelines before any options are applied
+func NewDefaultExport() Export {
+	return Export{
+		Named:   "otlpgrpc",
+		Headers: map[string]string{},
+	}
+}
+
 func NewDefault() *Config {
 	return &Config{
 		Metrics: Metrics{
-			Enable: false,
-			Export: Export{
-				Named:   "otlpgrpc",
-				Headers: map[string]string{},
-			},
+			Enable:        false,
+			Export:        NewDefaultExport(),
 			CollectPeriod: time.Second,
+			PushTimeout:   10 * time.Second,
 		},
 		Tracing: Tracing{
 			Enable: false,
-			Export: Export{
-				Named:   "otlpgrpc",
-				Headers: map[string]string{},
-			},
+			Export: NewDefaultExport(),
 			Propagators: []string{
 				"baggage",
 				"tracecontext",
 			},
 			Sample: false,
 		},
+		Logs: Logs{
+			Enable: false,
+			Export: NewDefaultExport(),
+		},
+		OnFailure:  FailurePolicyAbort,
 		errHandler: otel.GetErrorHandler(),
-		resource:   resource.Default(),
+		resources:  []resourceEntry{{source: ResourceSourceDefault, origin: "default", res: resource.Default()}},
 	}
 }
 
@@ -87,13 +389,18 @@ func (c *Config) Apply(opts ...OptionFunc) (err error) {
 	for _, opt := range opts {
 		err = multierr.Append(err, opt(c))
 	}
+	if c.Tracing.Enable {
+		err, c.envErrs.tracing = multierr.Append(err, c.envErrs.tracing), nil
+	}
+	if c.Metrics.Enable {
+		err, c.envErrs.metrics = multierr.Append(err, c.envErrs.metrics), nil
+	}
+	if c.Logs.Enable {
+		err, c.envErrs.logs = multierr.Append(err, c.envErrs.logs), nil
+	}
 	return err
 }
 
 func (c *Config) GetErrorHandler() otel.ErrorHandler {
 	return c.errHandler
 }
-
-func (c *Config) GetResource() *resource.Resource {
-	return c.resource
-}
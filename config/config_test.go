@@ -79,3 +79,27 @@ func TestApplyingConfig(t *testing.T) {
 	assert.Equal(t, map[string]string{"Service-Domain": "pineapples"}, conf.Tracing.Export.Headers)
 	assert.Equal(t, []string{"b3", "ot"}, conf.Tracing.Propagators)
 }
+
+func TestExportEndpoint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		export   config.Export
+		hostPort string
+		urlPath  string
+		insecure bool
+	}{
+		{export: config.Export{Endpoint: "http://localhost:4318"}, hostPort: "localhost:4318", insecure: true},
+		{export: config.Export{Endpoint: "http://localhost:4318/"}, hostPort: "localhost:4318", insecure: true},
+		{export: config.Export{Endpoint: "https://collector.internal:4317/v1/traces"}, hostPort: "collector.internal:4317", urlPath: "/v1/traces"},
+		{export: config.Export{Endpoint: "https://gateway.internal/otlp/custom"}, hostPort: "gateway.internal", urlPath: "/otlp/custom"},
+		{export: config.Export{Endpoint: "https://collector.internal", AllowInsecure: true}, hostPort: "collector.internal", insecure: true},
+		{export: config.Export{}, hostPort: ""},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.hostPort, tc.export.HostPort(), "Must return the host and port of %q", tc.export.Endpoint)
+		assert.Equal(t, tc.urlPath, tc.export.URLPath("traces"), "Must return the path of %q", tc.export.Endpoint)
+		assert.Equal(t, tc.insecure, tc.export.Insecure(), "Must return if %q is insecure", tc.export.Endpoint)
+	}
+}
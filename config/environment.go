@@ -0,0 +1,484 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.uber.org/multierr"
+)
+
+// Environment variables defined by the Open Telemetry SDK specification
+// https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/sdk-environment-variables.md
+const (
+	EnvServiceName        = "OTEL_SERVICE_NAME"
+	EnvResourceAttributes = "OTEL_RESOURCE_ATTRIBUTES"
+	EnvPropagators        = "OTEL_PROPAGATORS"
+	EnvTracesSampler      = "OTEL_TRACES_SAMPLER"
+	EnvTracesSamplerArg   = "OTEL_TRACES_SAMPLER_ARG"
+	EnvTracesExporter     = "OTEL_TRACES_EXPORTER"
+	EnvMetricsExporter    = "OTEL_METRICS_EXPORTER"
+	EnvLogsExporter       = "OTEL_LOGS_EXPORTER"
+	EnvMetricsInterval    = "OTEL_METRIC_EXPORT_INTERVAL"
+	EnvMetricsTimeout     = "OTEL_METRIC_EXPORT_TIMEOUT"
+
+	EnvBatchScheduleDelay      = "OTEL_BSP_SCHEDULE_DELAY"
+	EnvBatchExportTimeout      = "OTEL_BSP_EXPORT_TIMEOUT"
+	EnvBatchMaxQueueSize       = "OTEL_BSP_MAX_QUEUE_SIZE"
+	EnvBatchMaxExportBatchSize = "OTEL_BSP_MAX_EXPORT_BATCH_SIZE"
+
+	EnvLogsBatchScheduleDelay      = "OTEL_BLRP_SCHEDULE_DELAY"
+	EnvLogsBatchExportTimeout      = "OTEL_BLRP_EXPORT_TIMEOUT"
+	EnvLogsBatchMaxQueueSize       = "OTEL_BLRP_MAX_QUEUE_SIZE"
+	EnvLogsBatchMaxExportBatchSize = "OTEL_BLRP_MAX_EXPORT_BATCH_SIZE"
+
+	EnvAttributeCountLimit           = "OTEL_ATTRIBUTE_COUNT_LIMIT"
+	EnvAttributeValueLengthLimit     = "OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT"
+	EnvSpanAttributeCountLimit       = "OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT"
+	EnvSpanAttributeValueLengthLimit = "OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT"
+	EnvSpanEventCountLimit           = "OTEL_SPAN_EVENT_COUNT_LIMIT"
+	EnvSpanLinkCountLimit            = "OTEL_SPAN_LINK_COUNT_LIMIT"
+	EnvEventAttributeCountLimit      = "OTEL_EVENT_ATTRIBUTE_COUNT_LIMIT"
+	EnvLinkAttributeCountLimit       = "OTEL_LINK_ATTRIBUTE_COUNT_LIMIT"
+
+	EnvExporterOTLPEndpoint    = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvExporterOTLPHeaders     = "OTEL_EXPORTER_OTLP_HEADERS"
+	EnvExporterOTLPCompression = "OTEL_EXPORTER_OTLP_COMPRESSION"
+	EnvExporterOTLPInsecure    = "OTEL_EXPORTER_OTLP_INSECURE"
+	EnvExporterOTLPProtocol    = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	EnvExporterOTLPTimeout     = "OTEL_EXPORTER_OTLP_TIMEOUT"
+
+	EnvExporterOTLPCertificate       = "OTEL_EXPORTER_OTLP_CERTIFICATE"
+	EnvExporterOTLPClientCertificate = "OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE"
+	EnvExporterOTLPClientKey         = "OTEL_EXPORTER_OTLP_CLIENT_KEY"
+
+	EnvExporterJaegerEndpoint = "OTEL_EXPORTER_JAEGER_ENDPOINT"
+	EnvExporterZipkinEndpoint = "OTEL_EXPORTER_ZIPKIN_ENDPOINT"
+
+	EnvExporterPrometheusHost = "OTEL_EXPORTER_PROMETHEUS_HOST"
+	EnvExporterPrometheusPort = "OTEL_EXPORTER_PROMETHEUS_PORT"
+)
+
+// EnvResourceDetectors is a comma separated list of the built in
+// resource detectors to run, it is not defined by the specification
+const EnvResourceDetectors = "OTEL_RESOURCE_DETECTORS"
+
+// signalEnv returns the signal specific variant of an OTLP exporter variable,
+// ie OTEL_EXPORTER_OTLP_ENDPOINT becomes OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+func signalEnv(key, signal string) string {
+	return strings.Replace(key, "OTEL_EXPORTER_OTLP_", "OTEL_EXPORTER_OTLP_"+signal+"_", 1)
+}
+
+// FromEnvironment applies the Open Telemetry SDK environment variables
+// to the configuration. Options applied afterwards will override any values
+// read from the environment.
+func FromEnvironment() OptionFunc {
+	return fromEnvironment(os.LookupEnv)
+}
+
+func fromEnvironment(lookup func(key string) (string, bool)) OptionFunc {
+	return func(c *Config) (err error) {
+		if v, ok := lookup(EnvResourceAttributes); ok {
+			attrs, perr := parseEnvAttributes(v)
+			if perr != nil {
+				err = multierr.Append(err, envWrap(EnvResourceAttributes, perr))
+			} else {
+				c.addResource(ResourceSourceEnvironment, EnvResourceAttributes, resource.NewSchemaless(attrs...))
+			}
+		}
+		if v, ok := lookup(EnvResourceDetectors); ok {
+			var names []string
+			for _, name := range strings.Split(v, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names = append(names, name)
+				}
+			}
+			if len(names) != 0 {
+				err = multierr.Append(err, envWrap(EnvResourceDetectors, WithResourceDetectors(names...)(c)))
+			}
+		}
+		if v, ok := lookup(EnvServiceName); ok && v != "" {
+			c.addResource(ResourceSourceEnvironment, EnvServiceName, resource.NewSchemaless(semconv.ServiceNameKey.String(v)))
+		}
+		if v, ok := lookup(EnvPropagators); ok {
+			err = multierr.Append(err, envPropagators(&c.Tracing, v))
+		}
+		if v, ok := lookup(EnvTracesSampler); ok {
+			err = multierr.Append(err, envSampler(lookup, &c.Tracing, v))
+		}
+		if v, ok := lookup(EnvTracesExporter); ok {
+			enable, eerr := envExporters(&c.Tracing.Export, &c.Tracing.Exports, EnvTracesExporter, v)
+			c.Tracing.Enable, err = enable, multierr.Append(err, eerr)
+		}
+		if v, ok := lookup(EnvMetricsExporter); ok {
+			enable, eerr := envExporters(&c.Metrics.Export, &c.Metrics.Exports, EnvMetricsExporter, v)
+			c.Metrics.Enable, err = enable, multierr.Append(err, eerr)
+		}
+		if v, ok := lookup(EnvLogsExporter); ok {
+			enable, eerr := envExporters(&c.Logs.Export, &c.Logs.Exports, EnvLogsExporter, v)
+			c.Logs.Enable, err = enable, multierr.Append(err, eerr)
+		}
+		if v, ok := lookup(EnvMetricsInterval); ok {
+			ms, perr := envMilliseconds(EnvMetricsInterval, v)
+			if perr != nil {
+				err = multierr.Append(err, perr)
+			} else {
+				c.Metrics.CollectPeriod = ms
+			}
+		}
+		if v, ok := lookup(EnvMetricsTimeout); ok {
+			ms, perr := envMilliseconds(EnvMetricsTimeout, v)
+			if perr != nil {
+				err = multierr.Append(err, perr)
+			} else {
+				c.Metrics.PushTimeout = ms
+			}
+		}
+
+		err = multierr.Append(err, envBatch(lookup, &c.Tracing.Batch, spanBatchEnv))
+		err = multierr.Append(err, envBatch(lookup, &c.Logs.Batch, logsBatchEnv))
+		err = multierr.Append(err, envLimits(lookup, &c.Tracing.Limits))
+
+		// The exporter variables are only reported once their pipeline is enabled,
+		// since OTEL_EXPORTER_OTLP_* is commonly set for pipelines that are unused
+		c.envErrs.tracing = envExport(lookup, &c.Tracing.Export, "TRACES")
+		for i := range c.Tracing.Exports {
+			c.envErrs.tracing = multierr.Append(c.envErrs.tracing, envExport(lookup, &c.Tracing.Exports[i], "TRACES"))
+		}
+		c.envErrs.metrics = envExport(lookup, &c.Metrics.Export, "METRICS")
+		for i := range c.Metrics.Exports {
+			c.envErrs.metrics = multierr.Append(c.envErrs.metrics, envExport(lookup, &c.Metrics.Exports[i], "METRICS"))
+		}
+		c.envErrs.logs = envExport(lookup, &c.Logs.Export, "LOGS")
+		for i := range c.Logs.Exports {
+			c.envErrs.logs = multierr.Append(c.envErrs.logs, envExport(lookup, &c.Logs.Exports[i], "LOGS"))
+		}
+
+		return err
+	}
+}
+
+// envEndpoint sets the endpoint read from the environment, unlike WithExporterEndpoint
+// the host is not resolved so that a host only reachable once the application
+// is running does not fail the configuration
+func envEndpoint(e *Export, endpoint string) error {
+	u, err := parseEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	e.Endpoint, e.baseEndpoint = u.String(), false
+	return nil
+}
+
+// envExport reads the exporter specific variables,
+// where the signal specific variables take precedence over the generic ones
+func envExport(lookup func(key string) (string, bool), e *Export, signal string) (err error) {
+	read := func(key string) (string, string, bool) {
+		if v, ok := lookup(signalEnv(key, signal)); ok {
+			return signalEnv(key, signal), v, true
+		}
+		v, ok := lookup(key)
+		return key, v, ok
+	}
+
+	switch e.Named {
+	case "jaeger":
+		if v, ok := lookup(EnvExporterJaegerEndpoint); ok {
+			err = multierr.Append(err, envWrap(EnvExporterJaegerEndpoint, envEndpoint(e, v)))
+		}
+		return err
+	case "zipkin":
+		if v, ok := lookup(EnvExporterZipkinEndpoint); ok {
+			err = multierr.Append(err, envWrap(EnvExporterZipkinEndpoint, envEndpoint(e, v)))
+		}
+		return err
+	case "prometheus":
+		host, hok := lookup(EnvExporterPrometheusHost)
+		port, pok := lookup(EnvExporterPrometheusPort)
+		if !hok && !pok {
+			return nil
+		}
+		if host == "" {
+			host = "localhost"
+		}
+		if port == "" {
+			port = "9464"
+		}
+		if _, perr := strconv.ParseUint(port, 10, 16); perr != nil {
+			return fmt.Errorf("%s must be a port number: %w", EnvExporterPrometheusPort, ErrInvalidParam)
+		}
+		return envWrap(EnvExporterPrometheusHost, envEndpoint(e, "http://"+net.JoinHostPort(host, port)))
+	}
+	if !strings.HasPrefix(e.Named, "otlp") {
+		return nil
+	}
+
+	if key, v, ok := read(EnvExporterOTLPProtocol); ok {
+		switch v {
+		case "grpc":
+			e.Named = "otlpgrpc"
+		case "http/protobuf":
+			e.Named = "otlphttp"
+		default:
+			err = multierr.Append(err, fmt.Errorf("%s has unsupported protocol %q: %w", key, v, ErrInvalidParam))
+		}
+	}
+	if key, v, ok := read(EnvExporterOTLPEndpoint); ok {
+		if eerr := envEndpoint(e, v); eerr != nil {
+			err = multierr.Append(err, envWrap(key, eerr))
+		} else {
+			e.baseEndpoint = key == EnvExporterOTLPEndpoint
+		}
+	}
+	if key, v, ok := read(EnvExporterOTLPHeaders); ok {
+		headers, perr := parseEnvHeaders(v)
+		if perr != nil {
+			err = multierr.Append(err, envWrap(key, perr))
+		}
+		if e.Headers == nil {
+			e.Headers = make(map[string]string, len(headers))
+		}
+		if e.inherited == nil {
+			e.inherited = make(map[string]struct{}, len(headers))
+		}
+		for k, v := range headers {
+			e.Headers[k] = v
+			e.inherited[k] = struct{}{}
+		}
+	}
+	if key, v, ok := read(EnvExporterOTLPCompression); ok {
+		err = multierr.Append(err, envWrap(key, withExporterCompressionNamed(v)(e)))
+	}
+	if key, v, ok := read(EnvExporterOTLPInsecure); ok {
+		insecure, perr := strconv.ParseBool(v)
+		if perr != nil {
+			err = multierr.Append(err, fmt.Errorf("%s must be a boolean value: %w", key, ErrInvalidParam))
+		} else {
+			e.AllowInsecure = insecure
+		}
+	}
+	if key, v, ok := read(EnvExporterOTLPTimeout); ok {
+		timeout, perr := envMilliseconds(key, v)
+		if perr != nil {
+			err = multierr.Append(err, perr)
+		} else {
+			e.Timeout = timeout
+		}
+	}
+	if key, v, ok := read(EnvExporterOTLPCertificate); ok {
+		err = multierr.Append(err, envWrap(key, WithExporterCACertificate(v)(e)))
+	}
+	certKey, cert, certOK := read(EnvExporterOTLPClientCertificate)
+	_, clientKey, keyOK := read(EnvExporterOTLPClientKey)
+	if certOK || keyOK {
+		err = multierr.Append(err, envWrap(certKey, WithExporterClientCertificate(cert, clientKey)(e)))
+	}
+	return err
+}
+
+// envExporters maps the comma separated list of spec defined exporter names
+// onto the primary and additional exports, and returns if the pipeline should be enabled
+func envExporters(primary *Export, additional *[]Export, key, value string) (bool, error) {
+	names := strings.Split(value, ",")
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "none" {
+			if len(names) > 1 {
+				return false, fmt.Errorf("%s can not combine none with other exporters: %w", key, ErrInvalidParam)
+			}
+			return false, nil
+		}
+		if i == 0 {
+			envExporterNamed(primary, name)
+			continue
+		}
+		if name == "" {
+			return false, fmt.Errorf("%s has an empty exporter entry: %w", key, ErrInvalidParam)
+		}
+		e := NewDefaultExport()
+		envExporterNamed(&e, name)
+		*additional = append(*additional, e)
+	}
+	return true, nil
+}
+
+func envExporterNamed(e *Export, name string) {
+	switch name {
+	case "", "otlp":
+		if !strings.HasPrefix(e.Named, "otlp") {
+			e.Named = "otlpgrpc"
+		}
+	case "logging", "console":
+		e.Named = "stdout"
+	default:
+		e.Named = name
+	}
+}
+
+func envPropagators(t *Tracing, value string) error {
+	var use []string
+	for _, name := range strings.Split(value, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "tracecontext", "baggage", "b3", "b3multi", "ottrace", "none":
+			use = append(use, name)
+		case "":
+			// Ignore empty entries caused by trailing commas
+		default:
+			return fmt.Errorf("%s has unsupported propagator %q: %w", EnvPropagators, name, ErrInvalidParam)
+		}
+	}
+	return WithTracingPropagators(use...)(t)
+}
+
+// envSampler applies the sampler and its argument,
+// the argument defaults to 1.0 when it is not set
+func envSampler(lookup func(key string) (string, bool), t *Tracing, name string) error {
+	arg := 1.0
+	if v, ok := lookup(EnvTracesSamplerArg); ok && v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number: %w", EnvTracesSamplerArg, ErrInvalidParam)
+		}
+		arg = parsed
+	}
+	return envWrap(EnvTracesSampler, WithTracingSampler(name, arg)(t))
+}
+
+// batchEnv names the variables read by envBatch
+type batchEnv struct {
+	scheduleDelay, exportTimeout, maxQueueSize, maxExportBatchSize string
+}
+
+var (
+	spanBatchEnv = batchEnv{
+		scheduleDelay:      EnvBatchScheduleDelay,
+		exportTimeout:      EnvBatchExportTimeout,
+		maxQueueSize:       EnvBatchMaxQueueSize,
+		maxExportBatchSize: EnvBatchMaxExportBatchSize,
+	}
+	logsBatchEnv = batchEnv{
+		scheduleDelay:      EnvLogsBatchScheduleDelay,
+		exportTimeout:      EnvLogsBatchExportTimeout,
+		maxQueueSize:       EnvLogsBatchMaxQueueSize,
+		maxExportBatchSize: EnvLogsBatchMaxExportBatchSize,
+	}
+)
+
+// envBatch reads the batch span or log record processor variables
+func envBatch(lookup func(key string) (string, bool), b *Batch, keys batchEnv) (err error) {
+	for key, field := range map[string]*time.Duration{
+		keys.scheduleDelay: &b.Timeout,
+		keys.exportTimeout: &b.ExportTimeout,
+	} {
+		if v, ok := lookup(key); ok {
+			d, perr := envMilliseconds(key, v)
+			if perr != nil {
+				err = multierr.Append(err, perr)
+				continue
+			}
+			*field = d
+		}
+	}
+	for key, field := range map[string]*int{
+		keys.maxQueueSize:       &b.MaxQueueSize,
+		keys.maxExportBatchSize: &b.MaxExportBatchSize,
+	} {
+		if v, ok := lookup(key); ok {
+			n, perr := strconv.Atoi(v)
+			if perr != nil || n <= 0 {
+				err = multierr.Append(err, fmt.Errorf("%s must be a positive number: %w", key, ErrInvalidParam))
+				continue
+			}
+			*field = n
+		}
+	}
+	return err
+}
+
+// envLimits reads the span limit variables
+func envLimits(lookup func(key string) (string, bool), l *Limits) (err error) {
+	limit := func(key string, fields ...*int) {
+		v, ok := lookup(key)
+		if !ok {
+			return
+		}
+		n, perr := strconv.Atoi(v)
+		if perr != nil || n <= 0 {
+			err = multierr.Append(err, fmt.Errorf("%s must be a positive number: %w", key, ErrInvalidParam))
+			return
+		}
+		for _, field := range fields {
+			*field = n
+		}
+	}
+	// The general attribute limits are read first
+	// so that the span specific variables override them
+	limit(EnvAttributeCountLimit, &l.AttributeCount, &l.AttributePerEventCount, &l.AttributePerLinkCount)
+	limit(EnvAttributeValueLengthLimit, &l.AttributeValueLength)
+	limit(EnvSpanAttributeCountLimit, &l.AttributeCount)
+	limit(EnvSpanAttributeValueLengthLimit, &l.AttributeValueLength)
+	limit(EnvSpanEventCountLimit, &l.EventCount)
+	limit(EnvSpanLinkCountLimit, &l.LinkCount)
+	limit(EnvEventAttributeCountLimit, &l.AttributePerEventCount)
+	limit(EnvLinkAttributeCountLimit, &l.AttributePerLinkCount)
+	return err
+}
+
+func envMilliseconds(key, value string) (time.Duration, error) {
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number of milliseconds: %w", key, ErrInvalidParam)
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func envWrap(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", key, err)
+}
+
+// parseEnvHeaders parses the W3C baggage like format used
+// by the OTLP exporter headers, ie `key1=value1,key2=value2`
+func parseEnvHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("malformed entry %q: %w", entry, ErrInvalidParam)
+		}
+		k, err := url.QueryUnescape(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, fmt.Errorf("malformed key %q: %w", parts[0], ErrInvalidParam)
+		}
+		v, err := url.QueryUnescape(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("malformed value %q: %w", parts[1], ErrInvalidParam)
+		}
+		headers[k] = v
+	}
+	return headers, nil
+}
+
+func parseEnvAttributes(value string) ([]attribute.KeyValue, error) {
+	headers, err := parseEnvHeaders(value)
+	if err != nil {
+		return nil, err
+	}
+	attrs := make([]attribute.KeyValue, 0, len(headers))
+	for k, v := range headers {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return attrs, nil
+}
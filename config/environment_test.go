@@ -0,0 +1,288 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
+)
+
+func TestEnvironmentConfig(t *testing.T) {
+	t.Setenv(config.EnvServiceName, "checkout")
+	t.Setenv(config.EnvResourceAttributes, "deployment.environment=prod,team=payments%20squad")
+	t.Setenv(config.EnvTracesExporter, "otlp")
+	t.Setenv(config.EnvMetricsExporter, "logging")
+	t.Setenv(config.EnvMetricsInterval, "5000")
+	t.Setenv(config.EnvMetricsTimeout, "2500")
+	t.Setenv(config.EnvPropagators, "tracecontext,b3multi")
+	t.Setenv(config.EnvTracesSampler, "parentbased_traceidratio")
+	t.Setenv(config.EnvTracesSamplerArg, "0.5")
+	t.Setenv(config.EnvExporterOTLPEndpoint, "http://localhost:4317")
+	t.Setenv(config.EnvExporterOTLPHeaders, "api-key=secret,tenant=pineapples")
+	t.Setenv(config.EnvExporterOTLPCompression, "gzip")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_INSECURE", "true")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "http/protobuf")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()), "Must not error with valid environment")
+
+	assert.True(t, conf.Tracing.Enable)
+	assert.Equal(t, config.Sampler{Name: config.SamplerParentBasedTraceIDRatio, Arg: 0.5}, conf.Tracing.Sampler)
+	assert.True(t, conf.Tracing.Export.AllowInsecure)
+	assert.True(t, conf.Tracing.Export.UseCompression)
+	assert.Equal(t, "otlphttp", conf.Tracing.Export.Named)
+	assert.Equal(t, "http://localhost:4317", conf.Tracing.Export.Endpoint)
+	assert.Equal(t, map[string]string{"api-key": "secret", "tenant": "pineapples"}, conf.Tracing.Export.Headers)
+	assert.Equal(t, []string{"tracecontext", "b3multi"}, conf.Tracing.Propagators)
+
+	assert.True(t, conf.Metrics.Enable)
+	assert.False(t, conf.Metrics.Export.AllowInsecure)
+	assert.Equal(t, "stdout", conf.Metrics.Export.Named)
+	assert.Equal(t, 5*time.Second, conf.Metrics.CollectPeriod)
+	assert.Equal(t, 2500*time.Millisecond, conf.Metrics.PushTimeout)
+
+	attrs := conf.GetResource().Set()
+	for _, expect := range []attribute.KeyValue{
+		semconv.ServiceNameKey.String("checkout"),
+		attribute.String("deployment.environment", "prod"),
+		attribute.String("team", "payments squad"),
+	} {
+		v, ok := attrs.Value(expect.Key)
+		assert.True(t, ok, "Must have the attribute %s", expect.Key)
+		assert.Equal(t, expect.Value, v)
+	}
+}
+
+func TestEnvironmentOverriddenByOptions(t *testing.T) {
+	t.Setenv(config.EnvTracesExporter, "zipkin")
+	t.Setenv(config.EnvExporterOTLPHeaders, "tenant=pineapples")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.FromEnvironment(),
+		config.WithTracesPipeline(
+			config.WithTracingExporterOptions(
+				config.WithExporterNamed("otlpgrpc"),
+				config.WithExporterHeaders(map[string]string{"tenant": "icecream"}),
+			),
+		),
+	), "Must allow options to override environment values")
+
+	assert.Equal(t, "otlpgrpc", conf.Tracing.Export.Named)
+	assert.Equal(t, map[string]string{"tenant": "icecream"}, conf.Tracing.Export.Headers)
+}
+
+func TestEnvironmentDisablesPipeline(t *testing.T) {
+	t.Setenv(config.EnvTracesExporter, "none")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.FromEnvironment(),
+	))
+	assert.False(t, conf.Tracing.Enable, "Must not enable the pipeline when set to none")
+}
+
+func TestInvalidEnvironmentValues(t *testing.T) {
+	testCases := []struct {
+		scenario string
+		key      string
+		value    string
+	}{
+		{scenario: "Malformed resource attributes", key: config.EnvResourceAttributes, value: "novalue"},
+		{scenario: "Malformed headers", key: config.EnvExporterOTLPHeaders, value: "=value"},
+		{scenario: "Unknown compression", key: config.EnvExporterOTLPCompression, value: "zstd"},
+		{scenario: "Non boolean insecure", key: config.EnvExporterOTLPInsecure, value: "maybe"},
+		{scenario: "Unknown protocol", key: config.EnvExporterOTLPProtocol, value: "http/json"},
+		{scenario: "Unknown propagator", key: config.EnvPropagators, value: "tracecontext,xray"},
+		{scenario: "Unknown sampler", key: config.EnvTracesSampler, value: "sometimes"},
+		{scenario: "Combined none exporter", key: config.EnvTracesExporter, value: "otlp,none"},
+		{scenario: "Invalid interval", key: config.EnvMetricsInterval, value: "-10"},
+		{scenario: "Invalid timeout", key: config.EnvMetricsTimeout, value: "0"},
+		{scenario: "Invalid exporter timeout", key: config.EnvExporterOTLPTimeout, value: "soon"},
+		{scenario: "Invalid batch delay", key: config.EnvBatchScheduleDelay, value: "-1"},
+		{scenario: "Invalid batch queue size", key: config.EnvBatchMaxQueueSize, value: "lots"},
+		{scenario: "Unknown resource detector", key: config.EnvResourceDetectors, value: "host,mainframe"},
+		{scenario: "Invalid attribute count limit", key: config.EnvAttributeCountLimit, value: "0"},
+		{scenario: "Invalid span event limit", key: config.EnvSpanEventCountLimit, value: "many"},
+		{scenario: "Invalid endpoint", key: config.EnvExporterOTLPEndpoint, value: "wss://localhost"},
+		{scenario: "Invalid logs batch delay", key: config.EnvLogsBatchScheduleDelay, value: "soon"},
+		{scenario: "Combined none logs exporter", key: config.EnvLogsExporter, value: "none,otlp"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Setenv(tc.key, tc.value)
+
+			err := config.NewDefault().Apply(config.FromEnvironment(), config.WithTracesPipeline())
+			assert.ErrorIs(t, err, config.ErrInvalidParam, "Must error with invalid environment value")
+			assert.Contains(t, err.Error(), "OTEL_", "Must reference the environment variable")
+		})
+	}
+}
+
+func TestInvalidEnvironmentExportOnlyAffectsEnabledPipelines(t *testing.T) {
+	t.Setenv(config.EnvExporterOTLPInsecure, "maybe")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "wss://localhost")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()), "Must not error while the pipelines are disabled")
+	assert.False(t, conf.Tracing.Export.AllowInsecure, "Must not change insecure with an invalid value")
+
+	err := conf.Apply(config.WithTracesPipeline())
+	assert.ErrorIs(t, err, config.ErrInvalidParam, "Must error once the pipeline is enabled")
+	assert.Contains(t, err.Error(), config.EnvExporterOTLPInsecure)
+	assert.NotContains(t, err.Error(), "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "Must not report the disabled pipeline")
+	assert.NoError(t, conf.Apply(), "Must only report the errors once")
+}
+
+func TestEnvironmentEndpointIsNotResolved(t *testing.T) {
+	t.Setenv(config.EnvTracesExporter, "otlp")
+	t.Setenv(config.EnvExporterOTLPEndpoint, "https://collector.invalid:4318")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()), "Must not resolve the endpoint host")
+	assert.Equal(t, "https://collector.invalid:4318", conf.Tracing.Export.Endpoint)
+}
+
+func TestEnvironmentMultipleExporters(t *testing.T) {
+	t.Setenv(config.EnvTracesExporter, "otlp,logging")
+	t.Setenv(config.EnvExporterOTLPEndpoint, "http://localhost:4317")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()))
+
+	assert.True(t, conf.Tracing.Enable)
+	assert.Equal(t, "otlpgrpc", conf.Tracing.Export.Named)
+	assert.Equal(t, "http://localhost:4317", conf.Tracing.Export.Endpoint)
+	if assert.Len(t, conf.Tracing.Exports, 1, "Must add the additional exporter") {
+		assert.Equal(t, "stdout", conf.Tracing.Exports[0].Named)
+	}
+}
+
+func TestEnvironmentEndpointPath(t *testing.T) {
+	t.Setenv(config.EnvTracesExporter, "otlp")
+	t.Setenv(config.EnvMetricsExporter, "otlp")
+	t.Setenv(config.EnvExporterOTLPProtocol, "http/protobuf")
+	t.Setenv(config.EnvExporterOTLPEndpoint, "https://localhost/otlp/")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "https://localhost/custom/metrics")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()))
+
+	assert.Equal(t, "/otlp/v1/traces", conf.Tracing.Export.URLPath("traces"), "Must append the signal's path to the base endpoint")
+	assert.Equal(t, "/custom/metrics", conf.Metrics.Export.URLPath("metrics"), "Must use the signal's endpoint as is")
+
+	require.NoError(t, conf.Apply(config.WithTracesPipeline(config.WithTracingExporterOptions(
+		config.WithExporterEndpoint("https://localhost/traces"),
+	))))
+	assert.Equal(t, "/traces", conf.Tracing.Export.URLPath("traces"), "Must use an endpoint set by options as is")
+}
+
+func TestInvalidEnvironmentSamplerArgument(t *testing.T) {
+	t.Setenv(config.EnvTracesSampler, config.SamplerTraceIDRatio)
+
+	for _, arg := range []string{"half", "1.5"} {
+		t.Setenv(config.EnvTracesSamplerArg, arg)
+
+		err := config.NewDefault().Apply(config.FromEnvironment())
+		assert.ErrorIs(t, err, config.ErrInvalidParam, "Must error with invalid sampler argument %s", arg)
+	}
+}
+
+func TestEnvironmentPrometheusExporter(t *testing.T) {
+	t.Setenv(config.EnvMetricsExporter, "prometheus")
+	t.Setenv(config.EnvExporterPrometheusHost, "0.0.0.0")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()))
+
+	assert.True(t, conf.Metrics.Enable)
+	assert.Equal(t, "prometheus", conf.Metrics.Export.Named)
+	assert.Equal(t, "http://0.0.0.0:9464", conf.Metrics.Export.Endpoint, "Must use the default port")
+
+	t.Setenv(config.EnvExporterPrometheusPort, "metrics")
+	assert.ErrorIs(t, config.NewDefault().Apply(config.FromEnvironment()), config.ErrInvalidParam)
+}
+
+func TestEnvironmentBatchSpanProcessor(t *testing.T) {
+	t.Setenv(config.EnvBatchScheduleDelay, "500")
+	t.Setenv(config.EnvBatchExportTimeout, "10000")
+	t.Setenv(config.EnvBatchMaxQueueSize, "4096")
+	t.Setenv(config.EnvBatchMaxExportBatchSize, "256")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()))
+
+	assert.Equal(t, config.Batch{
+		MaxQueueSize:       4096,
+		MaxExportBatchSize: 256,
+		Timeout:            500 * time.Millisecond,
+		ExportTimeout:      10 * time.Second,
+	}, conf.Tracing.Batch)
+}
+
+func TestEnvironmentSpanLimits(t *testing.T) {
+	t.Setenv(config.EnvAttributeCountLimit, "64")
+	t.Setenv(config.EnvAttributeValueLengthLimit, "1024")
+	t.Setenv(config.EnvSpanAttributeCountLimit, "32")
+	t.Setenv(config.EnvSpanEventCountLimit, "16")
+	t.Setenv(config.EnvSpanLinkCountLimit, "8")
+	t.Setenv(config.EnvLinkAttributeCountLimit, "4")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()))
+
+	assert.Equal(t, config.Limits{
+		AttributeCount:         32,
+		AttributeValueLength:   1024,
+		EventCount:             16,
+		LinkCount:              8,
+		AttributePerEventCount: 64,
+		AttributePerLinkCount:  4,
+	}, conf.Tracing.Limits, "Must use the span specific variables over the general attribute limits")
+}
+
+func TestEnvironmentResourceDetectors(t *testing.T) {
+	t.Setenv(config.EnvResourceDetectors, "host, process,,container")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.FromEnvironment(),
+		config.WithResourceDetectors(detectors.Process, detectors.Kubernetes),
+	))
+
+	assert.Equal(t, []string{
+		detectors.Host,
+		detectors.Process,
+		detectors.Container,
+		detectors.Kubernetes,
+	}, conf.ResourceDetectors, "Must not repeat detectors set by options and the environment")
+}
+
+func TestEnvironmentLogs(t *testing.T) {
+	t.Setenv(config.EnvLogsExporter, "otlp,console")
+	t.Setenv(config.EnvExporterOTLPEndpoint, "http://localhost:4317")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "http://localhost:4318")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_PROTOCOL", "http/protobuf")
+	t.Setenv(config.EnvLogsBatchScheduleDelay, "250")
+	t.Setenv(config.EnvLogsBatchMaxQueueSize, "512")
+	t.Setenv(config.EnvBatchMaxQueueSize, "4096")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()))
+
+	assert.True(t, conf.Logs.Enable)
+	assert.Equal(t, "otlphttp", conf.Logs.Export.Named)
+	assert.Equal(t, "http://localhost:4318", conf.Logs.Export.Endpoint, "Must prefer the logs specific endpoint")
+	if assert.Len(t, conf.Logs.Exports, 1, "Must add the additional exporter") {
+		assert.Equal(t, "stdout", conf.Logs.Exports[0].Named)
+	}
+	assert.Equal(t, config.Batch{MaxQueueSize: 512, Timeout: 250 * time.Millisecond}, conf.Logs.Batch)
+	assert.Equal(t, 4096, conf.Tracing.Batch.MaxQueueSize, "Must not apply the log record processor variables to spans")
+}
@@ -0,0 +1,583 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig defines the schema used by FromFile and FromReader
+type fileConfig struct {
+	Service fileService  `yaml:"service"`
+	Tracing *fileTracing `yaml:"tracing"`
+	Metrics *fileMetrics `yaml:"metrics"`
+	Logs    *fileLogs    `yaml:"logs"`
+}
+
+type fileService struct {
+	Name       string            `yaml:"name"`
+	Attributes map[string]string `yaml:"attributes"`
+	Detectors  []string          `yaml:"detectors"`
+}
+
+type fileExport struct {
+	Name           string            `yaml:"name"`
+	Endpoint       string            `yaml:"endpoint"`
+	Insecure       *bool             `yaml:"insecure"`
+	Compression    string            `yaml:"compression"`
+	Headers        map[string]string `yaml:"headers"`
+	TLS            *fileTLS          `yaml:"tls"`
+	Timeout        time.Duration     `yaml:"timeout"`
+	Retry          *fileRetry        `yaml:"retry"`
+	Path           string            `yaml:"path"`
+	MaxSize        int64             `yaml:"max_size"`
+	MaxBackups     int               `yaml:"max_backups"`
+	SyncEachExport bool              `yaml:"sync_each_export"`
+}
+
+type fileRetry struct {
+	Enabled         *bool         `yaml:"enabled"`
+	InitialInterval time.Duration `yaml:"initial_interval"`
+	MaxInterval     time.Duration `yaml:"max_interval"`
+	MaxElapsedTime  time.Duration `yaml:"max_elapsed_time"`
+}
+
+// option enables retrying using the intervals that are set,
+// the unset intervals use the OTLP exporter defaults
+func (fr *fileRetry) option() ExportOption {
+	r := NewDefaultRetry()
+	if fr.InitialInterval != 0 {
+		r.InitialInterval = fr.InitialInterval
+	}
+	if fr.MaxInterval != 0 {
+		r.MaxInterval = fr.MaxInterval
+	}
+	if fr.MaxElapsedTime != 0 {
+		r.MaxElapsedTime = fr.MaxElapsedTime
+	}
+	return WithExporterRetry(r.InitialInterval, r.MaxInterval, r.MaxElapsedTime)
+}
+
+type fileTLS struct {
+	CAFile     string `yaml:"ca_file"`
+	CertFile   string `yaml:"cert_file"`
+	KeyFile    string `yaml:"key_file"`
+	ServerName string `yaml:"server_name"`
+	MinVersion string `yaml:"min_version"`
+}
+
+type fileTracing struct {
+	Enabled     *bool         `yaml:"enabled"`
+	Export      *fileExport   `yaml:"export"`
+	Exports     []*fileExport `yaml:"exports"`
+	Sampler     string        `yaml:"sampler"`
+	SamplerArg  *float64      `yaml:"sampler_arg"`
+	Propagators []string      `yaml:"propagators"`
+
+	SamplingRules []fileSamplingRule `yaml:"sampling_rules"`
+
+	Batch       *fileBatch  `yaml:"batch"`
+	Synchronous bool        `yaml:"synchronous"`
+	Limits      *fileLimits `yaml:"limits"`
+}
+
+type fileLimits struct {
+	AttributeCount         int `yaml:"attribute_count"`
+	AttributeValueLength   int `yaml:"attribute_value_length"`
+	EventCount             int `yaml:"event_count"`
+	LinkCount              int `yaml:"link_count"`
+	AttributePerEventCount int `yaml:"attribute_per_event_count"`
+	AttributePerLinkCount  int `yaml:"attribute_per_link_count"`
+}
+
+type fileBatch struct {
+	MaxQueueSize       int           `yaml:"max_queue_size"`
+	MaxExportBatchSize int           `yaml:"max_export_batch_size"`
+	Timeout            time.Duration `yaml:"timeout"`
+	ExportTimeout      time.Duration `yaml:"export_timeout"`
+	BlockOnQueueFull   bool          `yaml:"block_on_queue_full"`
+}
+
+type fileSamplingRule struct {
+	SpanName          string            `yaml:"span_name"`
+	SpanKind          string            `yaml:"span_kind"`
+	Attributes        map[string]string `yaml:"attributes"`
+	AttributePatterns map[string]string `yaml:"attribute_patterns"`
+	Sampler           string            `yaml:"sampler"`
+	SamplerArg        *float64          `yaml:"sampler_arg"`
+}
+
+type fileMetrics struct {
+	Enabled       *bool         `yaml:"enabled"`
+	Export        *fileExport   `yaml:"export"`
+	Exports       []*fileExport `yaml:"exports"`
+	CollectPeriod time.Duration `yaml:"collect_period"`
+	PushTimeout   time.Duration `yaml:"push_timeout"`
+}
+
+type fileLogs struct {
+	Enabled *bool         `yaml:"enabled"`
+	Export  *fileExport   `yaml:"export"`
+	Exports []*fileExport `yaml:"exports"`
+	Batch   *fileBatch    `yaml:"batch"`
+}
+
+// fileOption is a configuration option paired with
+// the path of the field within the file it was read from
+type fileOption struct {
+	path []string
+	opt  OptionFunc
+}
+
+// FromFile reads the configuration file at path and applies it to the configuration,
+// see FromReader for the supported schema.
+func FromFile(path string) OptionFunc {
+	return func(c *Config) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := FromReader(f)(c); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return nil
+	}
+}
+
+// FromReader decodes the YAML (or JSON) document and applies it to the configuration.
+// Unknown fields are rejected and any invalid values are reported with their line number.
+// The supported schema is:
+//
+//	service:
+//	  name: checkout
+//	  attributes:
+//	    deployment.environment: production
+//	  detectors: [host, process, container]
+//	tracing:
+//	  enabled: true
+//	  sampler: parentbased_traceidratio
+//	  sampler_arg: 0.25
+//	  propagators: [tracecontext, baggage]
+//	  sampling_rules:
+//	    - span_kind: server
+//	      span_name: "GET *"
+//	      attributes:
+//	        http.target: /healthz
+//	      attribute_patterns:
+//	        http.user_agent: ^kube-probe/
+//	      sampler: ratelimited
+//	      sampler_arg: 1
+//	  batch:
+//	    max_queue_size: 4096
+//	    max_export_batch_size: 1024
+//	    timeout: 2s
+//	    export_timeout: 30s
+//	    block_on_queue_full: false
+//	  limits:
+//	    attribute_count: 128
+//	    attribute_value_length: 4096
+//	    event_count: 128
+//	    link_count: 128
+//	  export:
+//	    name: otlpgrpc
+//	    endpoint: http://localhost:4317
+//	    insecure: true
+//	    compression: gzip
+//	    headers:
+//	      api-key: secret
+//	    timeout: 10s
+//	    retry:
+//	      initial_interval: 1s
+//	      max_interval: 30s
+//	      max_elapsed_time: 5m
+//	    tls:
+//	      ca_file: /etc/ssl/collector-ca.pem
+//	      cert_file: /etc/ssl/client.pem
+//	      key_file: /etc/ssl/client-key.pem
+//	      server_name: collector.internal
+//	      min_version: "1.2"
+//	  exports:
+//	    - name: stdout
+//	    - name: file
+//	      path: /var/log/checkout/spans.jsonl
+//	      max_size: 104857600
+//	      max_backups: 5
+//	      sync_each_export: true
+//	metrics:
+//	  enabled: true
+//	  collect_period: 10s
+//	  push_timeout: 5s
+//	  export:
+//	    name: otlphttp
+//	logs:
+//	  enabled: true
+//	  batch:
+//	    timeout: 1s
+//	  export:
+//	    name: otlpgrpc
+//	  exports:
+//	    - name: file
+//	      path: /var/log/checkout/records.jsonl
+//
+// Defining the tracing, metrics or logs block will enable the pipeline unless `enabled: false` is set,
+// each entry of exports is an additional exporter used alongside export.
+// Setting `insecure: false` overrides OTEL_EXPORTER_OTLP_INSECURE, and the intervals
+// not set within a retry block use the defaults of the OTLP exporters.
+func FromReader(r io.Reader) OptionFunc {
+	return func(c *Config) error {
+		raw, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		var (
+			root yaml.Node
+			fc   fileConfig
+		)
+		if err := yaml.Unmarshal(raw, &root); err != nil {
+			return multierr.Append(err, ErrInvalidParam)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+			return multierr.Append(err, ErrInvalidParam)
+		}
+
+		var errs error
+		for _, fo := range fc.options() {
+			if err := fo.opt(c); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("line %d: %s: %w",
+					lineOf(&root, fo.path...),
+					strings.Join(fo.path, "."),
+					err,
+				))
+			}
+		}
+		return errs
+	}
+}
+
+func (fc *fileConfig) options() (opts []fileOption) {
+	if len(fc.Service.Attributes) != 0 {
+		attrs := make([]attribute.KeyValue, 0, len(fc.Service.Attributes))
+		for k, v := range fc.Service.Attributes {
+			attrs = append(attrs, attribute.String(k, v))
+		}
+		opts = append(opts, fileOption{path: []string{"service", "attributes"}, opt: WithAttributes(attrs...)})
+	}
+	if fc.Service.Name != "" {
+		opts = append(opts, fileOption{path: []string{"service", "name"}, opt: WithServiceName(fc.Service.Name)})
+	}
+	if len(fc.Service.Detectors) != 0 {
+		opts = append(opts, fileOption{path: []string{"service", "detectors"}, opt: WithResourceDetectors(fc.Service.Detectors...)})
+	}
+
+	opts = append(opts, fc.tracingOptions()...)
+	opts = append(opts, fc.metricsOptions()...)
+	return append(opts, fc.logsOptions()...)
+}
+
+func (fc *fileConfig) tracingOptions() (opts []fileOption) {
+	t := fc.Tracing
+	if t == nil {
+		return nil
+	}
+	if t.Enabled != nil && !*t.Enabled {
+		return []fileOption{{path: []string{"tracing", "enabled"}, opt: func(c *Config) error {
+			c.Tracing.Enable = false
+			return nil
+		}}}
+	}
+	opts = append(opts, fileOption{path: []string{"tracing"}, opt: WithTracesPipeline()})
+	if t.Sampler != "" {
+		opts = append(opts, fileOption{
+			path: []string{"tracing", "sampler"},
+			opt:  WithTracesPipeline(WithTracingSampler(t.Sampler, fileSamplerArg(t.SamplerArg))),
+		})
+	} else if t.SamplerArg != nil {
+		opts = append(opts, fileOption{path: []string{"tracing", "sampler_arg"}, opt: func(*Config) error {
+			return fmt.Errorf("sampler_arg requires a sampler: %w", ErrInvalidParam)
+		}})
+	}
+	for i, r := range t.SamplingRules {
+		opts = append(opts, fileOption{
+			path: []string{"tracing", "sampling_rules", strconv.Itoa(i)},
+			opt: WithTracesPipeline(WithTracingSamplingRules(SamplingRule{
+				SpanName:          r.SpanName,
+				SpanKind:          r.SpanKind,
+				Attributes:        r.Attributes,
+				AttributePatterns: r.AttributePatterns,
+				Sampler:           Sampler{Name: r.Sampler, Arg: fileSamplerArg(r.SamplerArg)},
+			})),
+		})
+	}
+	if t.Propagators != nil {
+		opts = append(opts, fileOption{
+			path: []string{"tracing", "propagators"},
+			opt:  WithTracesPipeline(WithTracingPropagators(t.Propagators...)),
+		})
+	}
+	opts = append(opts, t.Batch.options("tracing", func(opt batchOption) OptionFunc {
+		return WithTracesPipeline(func(t *Tracing) error { return opt(&t.Batch) })
+	})...)
+	opts = append(opts, t.Limits.options()...)
+	if t.Synchronous {
+		opts = append(opts, fileOption{path: []string{"tracing", "synchronous"}, opt: WithTracesPipeline(WithTracingSynchronousExport())})
+	}
+	for _, eo := range t.Export.options("tracing", "export") {
+		opts = append(opts, fileOption{path: eo.path, opt: WithTracesPipeline(WithTracingExporterOptions(eo.opt))})
+	}
+	for i, fe := range t.Exports {
+		path := []string{"tracing", "exports", strconv.Itoa(i)}
+		opts = append(opts, fileOption{path: path, opt: WithTracesPipeline(WithTracingAdditionalExporter(fe.additional(path)...))})
+	}
+	return opts
+}
+
+func (fc *fileConfig) metricsOptions() (opts []fileOption) {
+	m := fc.Metrics
+	if m == nil {
+		return nil
+	}
+	if m.Enabled != nil && !*m.Enabled {
+		return []fileOption{{path: []string{"metrics", "enabled"}, opt: func(c *Config) error {
+			c.Metrics.Enable = false
+			return nil
+		}}}
+	}
+	opts = append(opts, fileOption{path: []string{"metrics"}, opt: WithMetricsPipeline()})
+	if m.CollectPeriod != 0 {
+		opts = append(opts, fileOption{
+			path: []string{"metrics", "collect_period"},
+			opt:  WithMetricsPipeline(WithMetricsCollectionPeriod(m.CollectPeriod)),
+		})
+	}
+	if m.PushTimeout != 0 {
+		opts = append(opts, fileOption{
+			path: []string{"metrics", "push_timeout"},
+			opt:  WithMetricsPipeline(WithMetricsPushTimeout(m.PushTimeout)),
+		})
+	}
+	for _, eo := range m.Export.options("metrics", "export") {
+		opts = append(opts, fileOption{path: eo.path, opt: WithMetricsPipeline(WithMetricsExporterOptions(eo.opt))})
+	}
+	for i, fe := range m.Exports {
+		path := []string{"metrics", "exports", strconv.Itoa(i)}
+		opts = append(opts, fileOption{path: path, opt: WithMetricsPipeline(WithMetricsAdditionalExporter(fe.additional(path)...))})
+	}
+	return opts
+}
+
+func (fc *fileConfig) logsOptions() (opts []fileOption) {
+	l := fc.Logs
+	if l == nil {
+		return nil
+	}
+	if l.Enabled != nil && !*l.Enabled {
+		return []fileOption{{path: []string{"logs", "enabled"}, opt: func(c *Config) error {
+			c.Logs.Enable = false
+			return nil
+		}}}
+	}
+	opts = append(opts, fileOption{path: []string{"logs"}, opt: WithLogsPipeline()})
+	opts = append(opts, l.Batch.options("logs", func(opt batchOption) OptionFunc {
+		return WithLogsPipeline(func(l *Logs) error { return opt(&l.Batch) })
+	})...)
+	for _, eo := range l.Export.options("logs", "export") {
+		opts = append(opts, fileOption{path: eo.path, opt: WithLogsPipeline(WithLogsExporterOptions(eo.opt))})
+	}
+	for i, fe := range l.Exports {
+		path := []string{"logs", "exports", strconv.Itoa(i)}
+		opts = append(opts, fileOption{path: path, opt: WithLogsPipeline(WithLogsAdditionalExporter(fe.additional(path)...))})
+	}
+	return opts
+}
+
+// options returns the batch settings of the pipeline block,
+// apply converts each setting into an option for that pipeline
+func (fb *fileBatch) options(pipeline string, apply func(opt batchOption) OptionFunc) (opts []fileOption) {
+	if fb == nil {
+		return nil
+	}
+	add := func(field string, opt batchOption) {
+		opts = append(opts, fileOption{path: []string{pipeline, "batch", field}, opt: apply(opt)})
+	}
+	if fb.MaxQueueSize != 0 {
+		add("max_queue_size", batchMaxQueueSize(fb.MaxQueueSize))
+	}
+	if fb.MaxExportBatchSize != 0 {
+		add("max_export_batch_size", batchMaxExportBatchSize(fb.MaxExportBatchSize))
+	}
+	if fb.Timeout != 0 {
+		add("timeout", batchTimeout(fb.Timeout))
+	}
+	if fb.ExportTimeout != 0 {
+		add("export_timeout", batchExportTimeout(fb.ExportTimeout))
+	}
+	if fb.BlockOnQueueFull {
+		add("block_on_queue_full", batchBlockOnQueueFull())
+	}
+	return opts
+}
+
+func (fl *fileLimits) options() (opts []fileOption) {
+	if fl == nil {
+		return nil
+	}
+	for _, limit := range []struct {
+		field string
+		value int
+		opt   func(limit int) TracingOption
+	}{
+		{field: "attribute_count", value: fl.AttributeCount, opt: WithTracingAttributeCountLimit},
+		{field: "attribute_value_length", value: fl.AttributeValueLength, opt: WithTracingAttributeValueLengthLimit},
+		{field: "event_count", value: fl.EventCount, opt: WithTracingEventCountLimit},
+		{field: "link_count", value: fl.LinkCount, opt: WithTracingLinkCountLimit},
+		{field: "attribute_per_event_count", value: fl.AttributePerEventCount, opt: WithTracingAttributePerEventCountLimit},
+		{field: "attribute_per_link_count", value: fl.AttributePerLinkCount, opt: WithTracingAttributePerLinkCountLimit},
+	} {
+		if limit.value != 0 {
+			opts = append(opts, fileOption{
+				path: []string{"tracing", "limits", limit.field},
+				opt:  WithTracesPipeline(limit.opt(limit.value)),
+			})
+		}
+	}
+	return opts
+}
+
+// fileSamplerArg defaults the sampler argument to 1.0 when it is not set,
+// matching OTEL_TRACES_SAMPLER_ARG
+func fileSamplerArg(arg *float64) float64 {
+	if arg == nil {
+		return 1.0
+	}
+	return *arg
+}
+
+type fileExportOption struct {
+	path []string
+	opt  ExportOption
+}
+
+func (fe *fileExport) options(prefix ...string) (opts []fileExportOption) {
+	if fe == nil {
+		return nil
+	}
+	field := func(name string) []string {
+		return append(append([]string(nil), prefix...), name)
+	}
+	if fe.Name != "" {
+		opts = append(opts, fileExportOption{path: field("name"), opt: WithExporterNamed(fe.Name)})
+	}
+	if fe.Endpoint != "" {
+		opts = append(opts, fileExportOption{path: field("endpoint"), opt: WithExporterEndpoint(fe.Endpoint)})
+	}
+	if fe.Insecure != nil {
+		opts = append(opts, fileExportOption{path: field("insecure"), opt: withExporterInsecure(*fe.Insecure)})
+	}
+	if fe.Compression != "" {
+		opts = append(opts, fileExportOption{path: field("compression"), opt: withExporterCompressionNamed(fe.Compression)})
+	}
+	if fe.Headers != nil {
+		opts = append(opts, fileExportOption{path: field("headers"), opt: WithExporterHeaders(fe.Headers)})
+	}
+	if fe.Timeout != 0 {
+		opts = append(opts, fileExportOption{path: field("timeout"), opt: WithExporterTimeout(fe.Timeout)})
+	}
+	if fe.Path != "" {
+		opts = append(opts, fileExportOption{path: field("path"), opt: WithExporterPath(fe.Path)})
+	}
+	if fe.MaxSize != 0 {
+		opts = append(opts, fileExportOption{path: field("max_size"), opt: WithExporterFileMaxSize(fe.MaxSize)})
+	}
+	if fe.MaxBackups != 0 {
+		opts = append(opts, fileExportOption{path: field("max_backups"), opt: WithExporterFileMaxBackups(fe.MaxBackups)})
+	}
+	if fe.SyncEachExport {
+		opts = append(opts, fileExportOption{path: field("sync_each_export"), opt: WithExporterFileSyncEachExport()})
+	}
+	if r := fe.Retry; r != nil {
+		if r.Enabled != nil && !*r.Enabled {
+			opts = append(opts, fileExportOption{path: field("retry"), opt: WithExporterRetryDisabled()})
+		} else {
+			opts = append(opts, fileExportOption{path: field("retry"), opt: r.option()})
+		}
+	}
+	if t := fe.TLS; t != nil {
+		tlsField := func(name string) []string {
+			return append(field("tls"), name)
+		}
+		if t.CAFile != "" {
+			opts = append(opts, fileExportOption{path: tlsField("ca_file"), opt: WithExporterCACertificate(t.CAFile)})
+		}
+		if t.CertFile != "" || t.KeyFile != "" {
+			opts = append(opts, fileExportOption{path: tlsField("cert_file"), opt: WithExporterClientCertificate(t.CertFile, t.KeyFile)})
+		}
+		if t.ServerName != "" {
+			opts = append(opts, fileExportOption{path: tlsField("server_name"), opt: WithExporterServerName(t.ServerName)})
+		}
+		if t.MinVersion != "" {
+			opts = append(opts, fileExportOption{path: tlsField("min_version"), opt: withExporterMinTLSVersionNamed(t.MinVersion)})
+		}
+	}
+	return opts
+}
+
+// additional returns the export options used for an entry of exports,
+// where errors are annotated with the field name since they are reported
+// against the entry within the list
+func (fe *fileExport) additional(prefix []string) (opts []ExportOption) {
+	for _, eo := range fe.options(prefix...) {
+		eo := eo
+		opts = append(opts, func(e *Export) error {
+			if err := eo.opt(e); err != nil {
+				return fmt.Errorf("%s: %w", eo.path[len(eo.path)-1], err)
+			}
+			return nil
+		})
+	}
+	return opts
+}
+
+// lineOf returns the line number of the node found at path,
+// falling back to the closest parent that exists
+func lineOf(root *yaml.Node, path ...string) int {
+	n := root
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	line := n.Line
+	for _, key := range path {
+		var next *yaml.Node
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == key {
+					line, next = n.Content[i].Line, n.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(n.Content) {
+				line, next = n.Content[i].Line, n.Content[i]
+			}
+		}
+		if next == nil {
+			break
+		}
+		n = next
+	}
+	return line
+}
@@ -0,0 +1,272 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+)
+
+const validConfigFile = `
+service:
+  name: checkout
+  attributes:
+    deployment.environment: production
+  detectors: [host, container]
+tracing:
+  sampler: always_on
+  batch:
+    max_queue_size: 4096
+    max_export_batch_size: 1024
+    timeout: 2s
+    export_timeout: 10s
+    block_on_queue_full: true
+  limits:
+    attribute_count: 64
+    attribute_value_length: 256
+    event_count: 32
+  propagators: [tracecontext, b3]
+  sampling_rules:
+    - span_kind: server
+      attributes:
+        http.target: /healthz
+      sampler: always_off
+  export:
+    name: otlphttp
+    endpoint: http://localhost:4318
+    insecure: true
+    compression: gzip
+    headers:
+      api-key: secret
+    timeout: 3s
+    retry:
+      initial_interval: 1s
+      max_interval: 10s
+      max_elapsed_time: 1m
+  exports:
+    - name: stdout
+    - name: zipkin
+      endpoint: http://localhost:9411
+      retry:
+        enabled: false
+metrics:
+  collect_period: 30s
+  push_timeout: 5s
+  export:
+    name: stdout
+logs:
+  batch:
+    max_queue_size: 1024
+    timeout: 500ms
+  export:
+    name: otlpgrpc
+    endpoint: http://localhost:4317
+  exports:
+    - name: file
+      path: /var/log/checkout/records.jsonl
+      max_size: 1048576
+      max_backups: 3
+      sync_each_export: true
+`
+
+func TestConfigFromFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "otel.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(validConfigFile), 0o600))
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromFile(path)), "Must not error with a valid config file")
+
+	assert.True(t, conf.Tracing.Enable)
+	assert.Equal(t, config.Sampler{Name: config.SamplerAlwaysOn}, conf.Tracing.Sampler)
+	assert.Equal(t, config.Batch{
+		MaxQueueSize:       4096,
+		MaxExportBatchSize: 1024,
+		Timeout:            2 * time.Second,
+		ExportTimeout:      10 * time.Second,
+		BlockOnQueueFull:   true,
+	}, conf.Tracing.Batch)
+	assert.False(t, conf.Tracing.Synchronous)
+	assert.Equal(t, config.Limits{AttributeCount: 64, AttributeValueLength: 256, EventCount: 32}, conf.Tracing.Limits)
+	assert.True(t, conf.Tracing.Export.AllowInsecure)
+	assert.True(t, conf.Tracing.Export.UseCompression)
+	assert.Equal(t, "otlphttp", conf.Tracing.Export.Named)
+	assert.Equal(t, "http://localhost:4318", conf.Tracing.Export.Endpoint)
+	assert.Equal(t, map[string]string{"api-key": "secret"}, conf.Tracing.Export.Headers)
+	assert.Equal(t, 3*time.Second, conf.Tracing.Export.Timeout)
+	assert.Equal(t, &config.Retry{
+		Enabled:         true,
+		InitialInterval: time.Second,
+		MaxInterval:     10 * time.Second,
+		MaxElapsedTime:  time.Minute,
+	}, conf.Tracing.Export.Retry)
+	assert.Equal(t, []string{"tracecontext", "b3"}, conf.Tracing.Propagators)
+	assert.Equal(t, []config.SamplingRule{{
+		SpanKind:   "server",
+		Attributes: map[string]string{"http.target": "/healthz"},
+		Sampler:    config.Sampler{Name: config.SamplerAlwaysOff},
+	}}, conf.Tracing.SamplingRules)
+	if assert.Len(t, conf.Tracing.Exports, 2, "Must have configured the additional exporters") {
+		assert.Equal(t, "stdout", conf.Tracing.Exports[0].Named)
+		assert.Equal(t, "zipkin", conf.Tracing.Exports[1].Named)
+		assert.Equal(t, "http://localhost:9411", conf.Tracing.Exports[1].Endpoint)
+		assert.Equal(t, &config.Retry{Enabled: false}, conf.Tracing.Exports[1].Retry)
+	}
+
+	assert.True(t, conf.Metrics.Enable)
+	assert.Equal(t, "stdout", conf.Metrics.Export.Named)
+	assert.Equal(t, 30*time.Second, conf.Metrics.CollectPeriod)
+	assert.Equal(t, 5*time.Second, conf.Metrics.PushTimeout)
+
+	assert.True(t, conf.Logs.Enable)
+	assert.Equal(t, config.Batch{MaxQueueSize: 1024, Timeout: 500 * time.Millisecond}, conf.Logs.Batch)
+	assert.Equal(t, "otlpgrpc", conf.Logs.Export.Named)
+	assert.Equal(t, "http://localhost:4317", conf.Logs.Export.Endpoint)
+	if assert.Len(t, conf.Logs.Exports, 1, "Must have configured the additional exporter") {
+		assert.Equal(t, "file", conf.Logs.Exports[0].Named)
+		assert.Equal(t, "/var/log/checkout/records.jsonl", conf.Logs.Exports[0].Path)
+		assert.Equal(t, config.File{MaxSize: 1 << 20, MaxBackups: 3, SyncEachExport: true}, conf.Logs.Exports[0].File)
+	}
+
+	name, ok := conf.GetResource().Set().Value(semconv.ServiceNameKey)
+	assert.True(t, ok, "Must have set the service name")
+	assert.Equal(t, "checkout", name.AsString())
+	env, ok := conf.GetResource().Set().Value("deployment.environment")
+	assert.True(t, ok, "Must have set the service attributes")
+	assert.Equal(t, "production", env.AsString())
+	assert.Equal(t, []string{"host", "container"}, conf.ResourceDetectors)
+}
+
+func TestConfigFromJSONReader(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromReader(strings.NewReader(`{
+		"tracing": {"enabled": false},
+		"metrics": {"export": {"name": "otlpgrpc", "endpoint": "http://localhost:4317"}}
+	}`))), "Must not error with valid json")
+
+	assert.False(t, conf.Tracing.Enable)
+	assert.True(t, conf.Metrics.Enable)
+	assert.Equal(t, "http://localhost:4317", conf.Metrics.Export.Endpoint)
+}
+
+func TestConfigFromEmptyReader(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewDefault()
+	assert.NoError(t, conf.Apply(config.FromReader(strings.NewReader(""))), "Must not error with an empty document")
+	assert.False(t, conf.Tracing.Enable)
+	assert.False(t, conf.Metrics.Enable)
+}
+
+func TestInvalidConfigFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		document string
+		message  string
+	}{
+		{
+			scenario: "Unknown field",
+			document: "tracing:\n  exporter: stdout\n",
+			message:  "line 2",
+		},
+		{
+			scenario: "Invalid endpoint",
+			document: "service:\n  name: checkout\nmetrics:\n  export:\n    endpoint: wss://localhost\n",
+			message:  "line 5: metrics.export.endpoint",
+		},
+		{
+			scenario: "Invalid compression",
+			document: "tracing:\n  export:\n    compression: zstd\n",
+			message:  "line 3: tracing.export.compression",
+		},
+		{
+			scenario: "Invalid additional export",
+			document: "metrics:\n  exports:\n    - name: stdout\n    - compression: zstd\n",
+			message:  "line 4: metrics.exports.1: compression",
+		},
+		{
+			scenario: "Invalid sampler",
+			document: "tracing:\n  sampler: sometimes\n",
+			message:  "line 2: tracing.sampler",
+		},
+		{
+			scenario: "Invalid sampling rule",
+			document: "tracing:\n  sampling_rules:\n    - sampler: always_on\n    - span_kind: sideways\n      sampler: always_on\n",
+			message:  "line 4: tracing.sampling_rules.1",
+		},
+		{
+			scenario: "Invalid sampler ratio",
+			document: "tracing:\n  sampler: traceidratio\n  sampler_arg: 1.5\n",
+			message:  "line 2: tracing.sampler",
+		},
+		{
+			scenario: "Sampler ratio without sampler",
+			document: "tracing:\n  enabled: true\n  sampler_arg: 0.25\n",
+			message:  "line 3: tracing.sampler_arg",
+		},
+		{
+			scenario: "Invalid logs batch",
+			document: "logs:\n  batch:\n    max_export_batch_size: -1\n",
+			message:  "line 3: logs.batch.max_export_batch_size",
+		},
+		{
+			scenario: "Invalid file rotation",
+			document: "tracing:\n  export:\n    name: file\n    path: spans.jsonl\n    max_size: -1\n",
+			message:  "line 5: tracing.export.max_size",
+		},
+		{
+			scenario: "Malformed document",
+			document: "tracing: [",
+			message:  "yaml",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			err := config.NewDefault().Apply(config.FromReader(strings.NewReader(tc.document)))
+			assert.ErrorIs(t, err, config.ErrInvalidParam, "Must error with an invalid document")
+			assert.Contains(t, err.Error(), tc.message, "Must reference the invalid field")
+		})
+	}
+
+	assert.Error(t, config.NewDefault().Apply(config.FromFile(filepath.Join(t.TempDir(), "missing.yaml"))))
+}
+
+func TestConfigFileInsecureOverridesEnvironment(t *testing.T) {
+	t.Setenv(config.EnvExporterOTLPInsecure, "true")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.FromEnvironment(),
+		config.FromReader(strings.NewReader("tracing:\n  export:\n    insecure: false\n")),
+	))
+	assert.False(t, conf.Tracing.Export.AllowInsecure, "Must allow the file to disable insecure connections")
+}
+
+func TestConfigFileRetryDefaults(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromReader(strings.NewReader(
+		"tracing:\n  export:\n    retry:\n      enabled: true\n      max_interval: 1m\n",
+	))), "Must not require the intervals to be set")
+
+	expect := config.NewDefaultRetry()
+	expect.MaxInterval = time.Minute
+	assert.Equal(t, &expect, conf.Tracing.Export.Retry, "Must use the defaults for the unset intervals")
+}
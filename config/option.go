@@ -2,6 +2,8 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
@@ -12,19 +14,19 @@ import (
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
 )
 
 func WithServiceName(name string) OptionFunc {
 	return func(c *Config) error {
-		r, err := resource.Merge(c.GetResource(), resource.NewSchemaless(semconv.ServiceNameKey.String(name)))
-		if err != nil {
-			return err
-		}
-		c.resource = r
+		c.addResource(ResourceSourceExplicit, "WithServiceName", resource.NewSchemaless(semconv.ServiceNameKey.String(name)))
 		return nil
 	}
 }
 
+// WithResourceDetector adds the detected attributes to the resource,
+// they are overridden by attributes from the environment and explicit options
 func WithResourceDetector(ctx context.Context, detector resource.Detector) OptionFunc {
 	return func(c *Config) error {
 		if detector == nil {
@@ -35,19 +37,67 @@ func WithResourceDetector(ctx context.Context, detector resource.Detector) Optio
 		if err != nil {
 			return err
 		}
+		c.addResource(ResourceSourceDetector, "WithResourceDetector", r)
+		return nil
+	}
+}
 
-		c.resource, err = resource.Merge(c.resource, r)
-		return err
+// WithResourceDetectors runs the named built in detectors when the launcher starts,
+// the detected attributes are overridden by the attributes set using options.
+// The names are defined by the detectors package, ie detectors.Host
+func WithResourceDetectors(names ...string) OptionFunc {
+	return func(c *Config) error {
+		if len(names) == 0 {
+			return fmt.Errorf("no resource detectors defined: %w", ErrNilParamProvided)
+		}
+		known := make(map[string]bool)
+		for _, name := range detectors.Names() {
+			known[name] = true
+		}
+		for _, name := range names {
+			if !known[name] {
+				return fmt.Errorf("unknown resource detector %q: %w", name, ErrInvalidParam)
+			}
+		}
+		// Detectors already in use are not repeated
+		// when they are also read from the environment
+		used := make(map[string]bool)
+		for _, name := range c.ResourceDetectors {
+			used[name] = true
+		}
+		for _, name := range names {
+			if !used[name] {
+				used[name] = true
+				c.ResourceDetectors = append(c.ResourceDetectors, name)
+			}
+		}
+		return nil
+	}
+}
+
+// WithDetectorSource replaces what the detectors set by WithResourceDetectors read from,
+// ie the metadata service endpoints or how long the cloud detectors are able to take
+func WithDetectorSource(src detectors.Source) OptionFunc {
+	return func(c *Config) error {
+		c.detectorSource = src
+		return nil
 	}
 }
 
 func WithAttributes(attrs ...attribute.KeyValue) OptionFunc {
 	return func(c *Config) error {
-		r, err := resource.Merge(c.GetResource(), resource.NewSchemaless(attrs...))
-		if err != nil {
-			return err
-		}
-		c.resource = r
+		c.addResource(ResourceSourceExplicit, "WithAttributes", resource.NewSchemaless(attrs...))
+		return nil
+	}
+}
+
+// WithResourceConflictReporting sends all the conflicts found by Config.ResourceReport
+// to the error handler when the launcher starts, ie a detector disagreeing with
+// the service name set by WithServiceName.
+// Without it only the detectors disagreeing with each other are reported.
+func WithResourceConflictReporting() OptionFunc {
+	return func(c *Config) error {
+		c.ReportResourceConflicts = true
 		return nil
 	}
 }
@@ -62,6 +112,29 @@ func WithOtelErrorHandler(handler otel.ErrorHandler) OptionFunc {
 	}
 }
 
+// WithoutGlobals stops the launcher from setting the global providers,
+// propagator and error handler so that several launchers can be used
+// within the same process. The providers are accessed from the launcher instead.
+func WithoutGlobals() OptionFunc {
+	return func(c *Config) error {
+		c.DisableGlobals = true
+		return nil
+	}
+}
+
+// WithFailurePolicy sets how the launcher handles a pipeline that fails to start
+func WithFailurePolicy(policy FailurePolicy) OptionFunc {
+	return func(c *Config) error {
+		switch policy {
+		case FailurePolicyAbort, FailurePolicyNoop:
+			c.OnFailure = policy
+		default:
+			return fmt.Errorf("unknown failure policy %d: %w", policy, ErrInvalidParam)
+		}
+		return nil
+	}
+}
+
 func WithMetricsPipeline(pipeOpts ...MetricsOption) OptionFunc {
 	return func(c *Config) (err error) {
 		c.Metrics.Enable = true
@@ -88,6 +161,21 @@ func WithTracesPipeline(pipeOpts ...TracingOption) OptionFunc {
 	}
 }
 
+// WithLogsPipeline enables the logs pipeline,
+// records are emitted using the launcher's LoggerProvider
+func WithLogsPipeline(pipeOpts ...LogsOption) OptionFunc {
+	return func(c *Config) (err error) {
+		c.Logs.Enable = true
+		for _, opt := range pipeOpts {
+			if opt == nil {
+				return fmt.Errorf("nil logs pipeline option provided: %w", ErrNilParamProvided)
+			}
+			err = multierr.Append(err, opt(&c.Logs))
+		}
+		return err
+	}
+}
+
 func WithTracingExporterOptions(opts ...ExportOption) TracingOption {
 	return func(t *Tracing) (err error) {
 		for _, opt := range opts {
@@ -112,6 +200,104 @@ func WithMetricsExporterOptions(opts ...ExportOption) MetricsOption {
 	}
 }
 
+func WithLogsExporterOptions(opts ...ExportOption) LogsOption {
+	return func(l *Logs) (err error) {
+		for _, opt := range opts {
+			if opt == nil {
+				return fmt.Errorf("nil logs exporter option provided: %w", ErrNilParamProvided)
+			}
+			err = multierr.Append(err, opt(&l.Export))
+		}
+		return err
+	}
+}
+
+// WithTracingAdditionalExporter configures another exporter that spans are sent to
+// alongside the primary exporter, the exporter starts from the default export values.
+func WithTracingAdditionalExporter(opts ...ExportOption) TracingOption {
+	return func(t *Tracing) error {
+		e, err := newExport(opts...)
+		if err != nil {
+			return err
+		}
+		t.Exports = append(t.Exports, e)
+		return nil
+	}
+}
+
+// WithMetricsAdditionalExporter configures another exporter that metrics are sent to
+// alongside the primary exporter, the exporter starts from the default export values.
+func WithMetricsAdditionalExporter(opts ...ExportOption) MetricsOption {
+	return func(m *Metrics) error {
+		e, err := newExport(opts...)
+		if err != nil {
+			return err
+		}
+		m.Exports = append(m.Exports, e)
+		return nil
+	}
+}
+
+// WithLogsAdditionalExporter configures another exporter that log records are sent to
+// alongside the primary exporter, the exporter starts from the default export values.
+func WithLogsAdditionalExporter(opts ...ExportOption) LogsOption {
+	return func(l *Logs) error {
+		e, err := newExport(opts...)
+		if err != nil {
+			return err
+		}
+		l.Exports = append(l.Exports, e)
+		return nil
+	}
+}
+
+// WithLogsBatchMaxQueueSize sets the number of records buffered before they are dropped
+func WithLogsBatchMaxQueueSize(size int) LogsOption {
+	return func(l *Logs) error {
+		return batchMaxQueueSize(size)(&l.Batch)
+	}
+}
+
+// WithLogsBatchMaxExportBatchSize sets the maximum number of records sent in each export
+func WithLogsBatchMaxExportBatchSize(size int) LogsOption {
+	return func(l *Logs) error {
+		return batchMaxExportBatchSize(size)(&l.Batch)
+	}
+}
+
+// WithLogsBatchTimeout sets the longest time records are held before being exported
+func WithLogsBatchTimeout(timeout time.Duration) LogsOption {
+	return func(l *Logs) error {
+		return batchTimeout(timeout)(&l.Batch)
+	}
+}
+
+// WithLogsBatchExportTimeout limits how long each batch export is able to take
+func WithLogsBatchExportTimeout(timeout time.Duration) LogsOption {
+	return func(l *Logs) error {
+		return batchExportTimeout(timeout)(&l.Batch)
+	}
+}
+
+// WithLogsBatchBlockOnQueueFull waits for space in the queue when it is full
+// instead of dropping records, this will block the application when the exporter is slow.
+func WithLogsBatchBlockOnQueueFull() LogsOption {
+	return func(l *Logs) error {
+		return batchBlockOnQueueFull()(&l.Batch)
+	}
+}
+
+func newExport(opts ...ExportOption) (e Export, err error) {
+	e = NewDefaultExport()
+	for _, opt := range opts {
+		if opt == nil {
+			return e, fmt.Errorf("nil exporter option provided: %w", ErrNilParamProvided)
+		}
+		err = multierr.Append(err, opt(&e))
+	}
+	return e, err
+}
+
 func WithExporterInsecureConnection() ExportOption {
 	return func(p *Export) error {
 		p.AllowInsecure = true
@@ -119,6 +305,15 @@ func WithExporterInsecureConnection() ExportOption {
 	}
 }
 
+// withExporterInsecure sets whether the connection is insecure,
+// allowing a config file to override the environment in either direction
+func withExporterInsecure(insecure bool) ExportOption {
+	return func(p *Export) error {
+		p.AllowInsecure = insecure
+		return nil
+	}
+}
+
 func WithExporterUseCompression() ExportOption {
 	return func(p *Export) error {
 		p.UseCompression = true
@@ -126,31 +321,252 @@ func WithExporterUseCompression() ExportOption {
 	}
 }
 
+// withExporterCompressionNamed maps the spec defined compression names
+// onto the exporter configuration
+func withExporterCompressionNamed(name string) ExportOption {
+	return func(p *Export) error {
+		switch name {
+		case "gzip":
+			p.UseCompression = true
+		case "none", "":
+			p.UseCompression = false
+		default:
+			return fmt.Errorf("unsupported compression %q: %w", name, ErrInvalidParam)
+		}
+		return nil
+	}
+}
+
 // WithPipelineEndpoint will validate that the provided endpoint
 // has a valid schema and that the hostname can be resolved
 func WithExporterEndpoint(endpoint string) ExportOption {
 	return func(p *Export) error {
-		u, err := url.Parse(endpoint)
+		u, err := parseEndpoint(endpoint)
 		if err != nil {
 			return err
 		}
-		switch u.Scheme {
-		case "http", "https":
-			// expected schemas for the endpoint
-		default:
-			return fmt.Errorf("unknown scheme provided; must be http(s): %w", ErrInvalidParam)
-		}
 
 		if _, err := net.LookupHost(u.Hostname()); err != nil {
 			return multierr.Append(err, ErrInvalidParam)
 		}
 
 		p.Endpoint = u.String()
+		p.baseEndpoint = false
 
 		return nil
 	}
 }
 
+// parseEndpoint checks the endpoint is a http(s) URL
+func parseEndpoint(endpoint string) (*url.URL, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		// expected schemas for the endpoint
+	default:
+		return nil, fmt.Errorf("unknown scheme provided; must be http(s): %w", ErrInvalidParam)
+	}
+	return u, nil
+}
+
+// WithExporterCACertificate uses the PEM encoded certificate authorities
+// within the file to verify the exporter's endpoint
+func WithExporterCACertificate(path string) ExportOption {
+	return func(p *Export) error {
+		return p.withTLS(func(t *TLS) {
+			t.CAFile, t.CAPEM = path, nil
+		})
+	}
+}
+
+// WithExporterCACertificatePEM uses the PEM encoded certificate authorities
+// to verify the exporter's endpoint
+func WithExporterCACertificatePEM(pem []byte) ExportOption {
+	return func(p *Export) error {
+		if len(pem) == 0 {
+			return fmt.Errorf("certificate authority is empty: %w", ErrNilParamProvided)
+		}
+		return p.withTLS(func(t *TLS) {
+			t.CAFile, t.CAPEM = "", pem
+		})
+	}
+}
+
+// WithExporterClientCertificate uses the PEM encoded certificate and key files
+// to authenticate with the exporter's endpoint
+func WithExporterClientCertificate(certPath, keyPath string) ExportOption {
+	return func(p *Export) error {
+		return p.withTLS(func(t *TLS) {
+			t.CertFile, t.KeyFile, t.CertPEM, t.KeyPEM = certPath, keyPath, nil, nil
+		})
+	}
+}
+
+// WithExporterClientCertificatePEM uses the PEM encoded certificate and key
+// to authenticate with the exporter's endpoint
+func WithExporterClientCertificatePEM(cert, key []byte) ExportOption {
+	return func(p *Export) error {
+		if len(cert) == 0 || len(key) == 0 {
+			return fmt.Errorf("client certificate or key is empty: %w", ErrNilParamProvided)
+		}
+		return p.withTLS(func(t *TLS) {
+			t.CertFile, t.KeyFile, t.CertPEM, t.KeyPEM = "", "", cert, key
+		})
+	}
+}
+
+// WithExporterServerName overrides the name used to verify the endpoint's certificate
+func WithExporterServerName(name string) ExportOption {
+	return func(p *Export) error {
+		if name == "" {
+			return fmt.Errorf("server name is empty: %w", ErrInvalidParam)
+		}
+		return p.withTLS(func(t *TLS) {
+			t.ServerName = name
+		})
+	}
+}
+
+// WithExporterMinTLSVersion sets the minimum TLS version accepted, ie tls.VersionTLS12
+func WithExporterMinTLSVersion(version uint16) ExportOption {
+	return func(p *Export) error {
+		if version < tls.VersionTLS10 || version > tls.VersionTLS13 {
+			return fmt.Errorf("unknown tls version %#x: %w", version, ErrInvalidParam)
+		}
+		return p.withTLS(func(t *TLS) {
+			t.MinVersion = version
+		})
+	}
+}
+
+// withExporterMinTLSVersionNamed accepts the version names
+// used by the configuration file, ie 1.2
+func withExporterMinTLSVersionNamed(name string) ExportOption {
+	return func(p *Export) error {
+		version, ok := tlsVersions[name]
+		if !ok {
+			return fmt.Errorf("unknown tls version %q, must be one of 1.0, 1.1, 1.2 or 1.3: %w", name, ErrInvalidParam)
+		}
+		return WithExporterMinTLSVersion(version)(p)
+	}
+}
+
+// withTLS applies the update once the resulting
+// TLS settings are able to be loaded
+func (p *Export) withTLS(update func(t *TLS)) error {
+	t := p.TLS
+	update(&t)
+	if _, err := t.ClientConfig(); err != nil {
+		if !errors.Is(err, ErrInvalidParam) {
+			err = multierr.Append(err, ErrInvalidParam)
+		}
+		return err
+	}
+	p.TLS = t
+	return nil
+}
+
+// WithExporterTimeout limits how long each export request is able to take
+func WithExporterTimeout(timeout time.Duration) ExportOption {
+	return func(p *Export) error {
+		if timeout <= 0 {
+			return fmt.Errorf("timeout must be positive value: %w", ErrInvalidParam)
+		}
+		p.Timeout = timeout
+		return nil
+	}
+}
+
+// WithExporterRetry enables retrying failed exports, waiting initial after the first failure
+// and doubling the wait up to max between each attempt until maxElapsed has passed.
+// The otlphttp metrics exporter only supports a base interval, it makes up to five
+// attempts with a randomised exponential backoff from initial and ignores max and maxElapsed.
+func WithExporterRetry(initial, max, maxElapsed time.Duration) ExportOption {
+	return func(p *Export) error {
+		if initial <= 0 || max <= 0 || maxElapsed <= 0 {
+			return fmt.Errorf("retry intervals must be positive values: %w", ErrInvalidParam)
+		}
+		if initial > max {
+			return fmt.Errorf("retry initial interval %s exceeds max interval %s: %w", initial, max, ErrInvalidParam)
+		}
+		p.Retry = &Retry{
+			Enabled:         true,
+			InitialInterval: initial,
+			MaxInterval:     max,
+			MaxElapsedTime:  maxElapsed,
+		}
+		return nil
+	}
+}
+
+// WithExporterRetryDisabled drops the data from failed exports without retrying
+func WithExporterRetryDisabled() ExportOption {
+	return func(p *Export) error {
+		p.Retry = &Retry{Enabled: false}
+		return nil
+	}
+}
+
+// WithExporterServeMux registers the exporter's handler on the mux,
+// this is used by the prometheus exporter to serve /metrics on an existing server
+func WithExporterServeMux(mux ServeMux) ExportOption {
+	return func(p *Export) error {
+		if mux == nil {
+			return fmt.Errorf("serve mux is nil: %w", ErrNilParamProvided)
+		}
+		p.Mux = mux
+		return nil
+	}
+}
+
+// WithExporterPath sets the file written to by the file exporter
+func WithExporterPath(path string) ExportOption {
+	return func(p *Export) error {
+		if path == "" {
+			return fmt.Errorf("no exporter path defined: %w", ErrInvalidParam)
+		}
+		p.Path = path
+		return nil
+	}
+}
+
+// WithExporterFileMaxSize rotates the file exporter's file once
+// writing an export would take it over size bytes
+func WithExporterFileMaxSize(size int64) ExportOption {
+	return func(p *Export) error {
+		if size <= 0 {
+			return fmt.Errorf("file max size must be a positive value: %w", ErrInvalidParam)
+		}
+		p.File.MaxSize = size
+		return nil
+	}
+}
+
+// WithExporterFileMaxBackups removes the oldest rotated files once there are more than count,
+// a count of zero keeps every rotated file
+func WithExporterFileMaxBackups(count int) ExportOption {
+	return func(p *Export) error {
+		if count < 0 {
+			return fmt.Errorf("file max backups must not be negative: %w", ErrInvalidParam)
+		}
+		p.File.MaxBackups = count
+		return nil
+	}
+}
+
+// WithExporterFileSyncEachExport flushes the file exporter's file to disk after each export
+// so that exported data is not lost if the host fails, the file is always flushed when
+// the exporter is shutdown
+func WithExporterFileSyncEachExport() ExportOption {
+	return func(p *Export) error {
+		p.File.SyncEachExport = true
+		return nil
+	}
+}
+
 func WithExporterHeaders(headers map[string]string) ExportOption {
 	return func(p *Export) error {
 		if headers == nil {
@@ -162,7 +578,9 @@ func WithExporterHeaders(headers map[string]string) ExportOption {
 		}
 
 		for k, v := range headers {
-			if _, exist := p.Headers[k]; exist {
+			if _, inherited := p.inherited[k]; inherited {
+				delete(p.inherited, k)
+			} else if _, exist := p.Headers[k]; exist {
 				return fmt.Errorf("conflict in head key %s: %w", k, ErrInvalidParam)
 			}
 			p.Headers[k] = v
@@ -192,6 +610,9 @@ func WithTracingPropagators(use ...string) TracingOption {
 	}
 }
 
+// WithTracingSampled will sample every span.
+//
+// Deprecated: use WithTracingSampler with the always_on sampler instead
 func WithTracingSampled() TracingOption {
 	return func(t *Tracing) error {
 		t.Sample = true
@@ -199,12 +620,208 @@ func WithTracingSampled() TracingOption {
 	}
 }
 
+// WithTracingSampler sets the sampler used by the tracing pipeline,
+// arg is the ratio for traceidratio samplers (between 0 and 1)
+// or the traces per second for ratelimited samplers (greater than 0)
+// and is ignored by the remaining samplers.
+func WithTracingSampler(name string, arg float64) TracingOption {
+	return func(t *Tracing) error {
+		s, err := newSampler(name, arg)
+		if err != nil {
+			return err
+		}
+		t.Sampler = s
+		return nil
+	}
+}
+
+// WithTracingSamplingRules adds rules that are evaluated in order
+// before the configured sampler, the first rule that matches the span
+// will use its sampler to decide if the span is sampled.
+func WithTracingSamplingRules(rules ...SamplingRule) TracingOption {
+	return func(t *Tracing) error {
+		for i, rule := range rules {
+			if err := rule.Validate(); err != nil {
+				return fmt.Errorf("sampling rule %d: %w", i, err)
+			}
+			t.SamplingRules = append(t.SamplingRules, rule)
+		}
+		return nil
+	}
+}
+
+func newSampler(name string, arg float64) (Sampler, error) {
+	switch name {
+	case SamplerAlwaysOn, SamplerAlwaysOff, SamplerParentBasedAlwaysOn, SamplerParentBasedAlwaysOff:
+		arg = 0
+	case SamplerTraceIDRatio, SamplerParentBasedTraceIDRatio:
+		if arg < 0 || arg > 1 {
+			return Sampler{}, fmt.Errorf("sampler %s ratio %v must be between 0 and 1: %w", name, arg, ErrInvalidParam)
+		}
+	case SamplerRateLimited, SamplerParentBasedRateLimited:
+		if arg <= 0 {
+			return Sampler{}, fmt.Errorf("sampler %s rate %v must be greater than 0: %w", name, arg, ErrInvalidParam)
+		}
+	default:
+		return Sampler{}, fmt.Errorf("unsupported sampler %q: %w", name, ErrInvalidParam)
+	}
+	return Sampler{Name: name, Arg: arg}, nil
+}
+
+// WithTracingBatchMaxQueueSize sets the number of spans buffered before they are dropped
+func WithTracingBatchMaxQueueSize(size int) TracingOption {
+	return func(t *Tracing) error {
+		return batchMaxQueueSize(size)(&t.Batch)
+	}
+}
+
+// WithTracingBatchMaxExportBatchSize sets the maximum number of spans sent in each export
+func WithTracingBatchMaxExportBatchSize(size int) TracingOption {
+	return func(t *Tracing) error {
+		return batchMaxExportBatchSize(size)(&t.Batch)
+	}
+}
+
+// WithTracingBatchTimeout sets the longest time spans are held before being exported
+func WithTracingBatchTimeout(timeout time.Duration) TracingOption {
+	return func(t *Tracing) error {
+		return batchTimeout(timeout)(&t.Batch)
+	}
+}
+
+// WithTracingBatchExportTimeout limits how long each batch export is able to take
+func WithTracingBatchExportTimeout(timeout time.Duration) TracingOption {
+	return func(t *Tracing) error {
+		return batchExportTimeout(timeout)(&t.Batch)
+	}
+}
+
+// WithTracingBatchBlockOnQueueFull waits for space in the queue when it is full
+// instead of dropping spans, this will block the application when the exporter is slow.
+func WithTracingBatchBlockOnQueueFull() TracingOption {
+	return func(t *Tracing) error {
+		return batchBlockOnQueueFull()(&t.Batch)
+	}
+}
+
+// WithTracingSynchronousExport exports each span as it ends instead of batching them,
+// this is intended for short lived processes such as command line tools.
+func WithTracingSynchronousExport() TracingOption {
+	return func(t *Tracing) error {
+		t.Synchronous = true
+		return nil
+	}
+}
+
+// WithTracingAttributeCountLimit sets the maximum number of attributes recorded on each span
+func WithTracingAttributeCountLimit(limit int) TracingOption {
+	return withTracingLimit("attribute count", limit, func(l *Limits) *int { return &l.AttributeCount })
+}
+
+// WithTracingAttributeValueLengthLimit truncates string attribute values longer than limit characters
+func WithTracingAttributeValueLengthLimit(limit int) TracingOption {
+	return withTracingLimit("attribute value length", limit, func(l *Limits) *int { return &l.AttributeValueLength })
+}
+
+// WithTracingEventCountLimit sets the maximum number of events recorded on each span
+func WithTracingEventCountLimit(limit int) TracingOption {
+	return withTracingLimit("event count", limit, func(l *Limits) *int { return &l.EventCount })
+}
+
+// WithTracingLinkCountLimit sets the maximum number of links recorded on each span
+func WithTracingLinkCountLimit(limit int) TracingOption {
+	return withTracingLimit("link count", limit, func(l *Limits) *int { return &l.LinkCount })
+}
+
+// WithTracingAttributePerEventCountLimit sets the maximum number of attributes recorded on each span event
+func WithTracingAttributePerEventCountLimit(limit int) TracingOption {
+	return withTracingLimit("attribute per event count", limit, func(l *Limits) *int { return &l.AttributePerEventCount })
+}
+
+// WithTracingAttributePerLinkCountLimit sets the maximum number of attributes recorded on each span link
+func WithTracingAttributePerLinkCountLimit(limit int) TracingOption {
+	return withTracingLimit("attribute per link count", limit, func(l *Limits) *int { return &l.AttributePerLinkCount })
+}
+
+func withTracingLimit(name string, limit int, field func(l *Limits) *int) TracingOption {
+	return func(t *Tracing) error {
+		if limit <= 0 {
+			return fmt.Errorf("%s limit must be positive value: %w", name, ErrInvalidParam)
+		}
+		*field(&t.Limits) = limit
+		return nil
+	}
+}
+
+// batchOption updates the batch settings shared by the tracing and logs pipelines
+type batchOption func(b *Batch) error
+
+func batchMaxQueueSize(size int) batchOption {
+	return func(b *Batch) error {
+		if size <= 0 {
+			return fmt.Errorf("max queue size must be positive value: %w", ErrInvalidParam)
+		}
+		b.MaxQueueSize = size
+		return nil
+	}
+}
+
+func batchMaxExportBatchSize(size int) batchOption {
+	return func(b *Batch) error {
+		if size <= 0 {
+			return fmt.Errorf("max export batch size must be positive value: %w", ErrInvalidParam)
+		}
+		b.MaxExportBatchSize = size
+		return nil
+	}
+}
+
+func batchTimeout(timeout time.Duration) batchOption {
+	return func(b *Batch) error {
+		if timeout <= 0 {
+			return fmt.Errorf("batch timeout must be positive value: %w", ErrInvalidParam)
+		}
+		b.Timeout = timeout
+		return nil
+	}
+}
+
+func batchExportTimeout(timeout time.Duration) batchOption {
+	return func(b *Batch) error {
+		if timeout <= 0 {
+			return fmt.Errorf("export timeout must be positive value: %w", ErrInvalidParam)
+		}
+		b.ExportTimeout = timeout
+		return nil
+	}
+}
+
+func batchBlockOnQueueFull() batchOption {
+	return func(b *Batch) error {
+		b.BlockOnQueueFull = true
+		return nil
+	}
+}
+
+// WithMetricsCollectionPeriod sets how often metrics are collected and exported
 func WithMetricsCollectionPeriod(t time.Duration) MetricsOption {
 	return func(m *Metrics) error {
-		if t < 0 {
+		if t <= 0 {
 			return fmt.Errorf("collection period must be positive value: %w", ErrInvalidParam)
 		}
 		m.CollectPeriod = t
 		return nil
 	}
 }
+
+// WithMetricsPushTimeout limits how long each export is able to take
+// before it is cancelled.
+func WithMetricsPushTimeout(t time.Duration) MetricsOption {
+	return func(m *Metrics) error {
+		if t <= 0 {
+			return fmt.Errorf("push timeout must be positive value: %w", ErrInvalidParam)
+		}
+		m.PushTimeout = t
+		return nil
+	}
+}
@@ -3,6 +3,7 @@ package config_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/MovieStoreGuy/otel-go-starter/config"
 	"github.com/stretchr/testify/assert"
@@ -26,9 +27,15 @@ func TestNilParamConfigOptions(t *testing.T) {
 		{method: "WithPipelineHeaders", opt: config.WithMetricsPipeline(
 			config.WithMetricsExporterOptions(config.WithExporterHeaders(nil)),
 		)},
+		{method: "WithExporterServeMux", opt: config.WithMetricsPipeline(
+			config.WithMetricsExporterOptions(config.WithExporterServeMux(nil)),
+		)},
 		{method: "WithPipelinePropagators", opt: config.WithTracesPipeline(
 			config.WithTracingPropagators(),
 		)},
+		{method: "WithResourceDetectors", opt: config.WithResourceDetectors()},
+		{method: "WithLogsPipeline", opt: config.WithLogsPipeline(nil)},
+		{method: "WithLogsExporterOptions", opt: config.WithLogsPipeline(config.WithLogsExporterOptions(nil))},
 	}
 
 	for _, tc := range testCases {
@@ -58,6 +65,44 @@ func TestInvalidConfigOptions(t *testing.T) {
 			),
 		)},
 		{method: "WithPipelineExporter", opt: config.WithMetricsPipeline(config.WithMetricsExporterOptions(config.WithExporterNamed("")))},
+		{method: "WithMetricsCollectionPeriod", opt: config.WithMetricsPipeline(config.WithMetricsCollectionPeriod(0))},
+		{method: "WithMetricsPushTimeout", opt: config.WithMetricsPipeline(config.WithMetricsPushTimeout(-time.Second))},
+		{method: "WithExporterTimeout", opt: config.WithTracesPipeline(config.WithTracingExporterOptions(config.WithExporterTimeout(0)))},
+		{method: "WithExporterRetry.MissingInterval", opt: config.WithTracesPipeline(config.WithTracingExporterOptions(
+			config.WithExporterRetry(0, time.Second, time.Minute),
+		))},
+		{method: "WithExporterRetry.InitialExceedsMax", opt: config.WithMetricsPipeline(config.WithMetricsExporterOptions(
+			config.WithExporterRetry(time.Minute, time.Second, time.Minute),
+		))},
+		{method: "WithResourceDetectors", opt: config.WithResourceDetectors("host", "mainframe")},
+		{method: "WithTracingBatchMaxQueueSize", opt: config.WithTracesPipeline(config.WithTracingBatchMaxQueueSize(0))},
+		{method: "WithTracingBatchMaxExportBatchSize", opt: config.WithTracesPipeline(config.WithTracingBatchMaxExportBatchSize(-1))},
+		{method: "WithTracingBatchTimeout", opt: config.WithTracesPipeline(config.WithTracingBatchTimeout(0))},
+		{method: "WithTracingBatchExportTimeout", opt: config.WithTracesPipeline(config.WithTracingBatchExportTimeout(0))},
+		{method: "WithTracingAttributeCountLimit", opt: config.WithTracesPipeline(config.WithTracingAttributeCountLimit(0))},
+		{method: "WithTracingAttributeValueLengthLimit", opt: config.WithTracesPipeline(config.WithTracingAttributeValueLengthLimit(-1))},
+		{method: "WithTracingEventCountLimit", opt: config.WithTracesPipeline(config.WithTracingEventCountLimit(0))},
+		{method: "WithTracingLinkCountLimit", opt: config.WithTracesPipeline(config.WithTracingLinkCountLimit(0))},
+		{method: "WithTracingAttributePerEventCountLimit", opt: config.WithTracesPipeline(config.WithTracingAttributePerEventCountLimit(0))},
+		{method: "WithTracingAttributePerLinkCountLimit", opt: config.WithTracesPipeline(config.WithTracingAttributePerLinkCountLimit(0))},
+		{method: "WithExporterPath", opt: config.WithLogsPipeline(config.WithLogsExporterOptions(config.WithExporterPath("")))},
+		{method: "WithExporterFileMaxSize", opt: config.WithTracesPipeline(config.WithTracingExporterOptions(config.WithExporterFileMaxSize(0)))},
+		{method: "WithExporterFileMaxBackups", opt: config.WithMetricsPipeline(config.WithMetricsExporterOptions(config.WithExporterFileMaxBackups(-1)))},
+		{method: "WithLogsBatchMaxQueueSize", opt: config.WithLogsPipeline(config.WithLogsBatchMaxQueueSize(0))},
+		{method: "WithLogsBatchMaxExportBatchSize", opt: config.WithLogsPipeline(config.WithLogsBatchMaxExportBatchSize(-1))},
+		{method: "WithLogsBatchTimeout", opt: config.WithLogsPipeline(config.WithLogsBatchTimeout(0))},
+		{method: "WithLogsBatchExportTimeout", opt: config.WithLogsPipeline(config.WithLogsBatchExportTimeout(0))},
+		{method: "WithFailurePolicy", opt: config.WithFailurePolicy(config.FailurePolicy(-1))},
+		{method: "WithTracingSampler.Unknown", opt: config.WithTracesPipeline(config.WithTracingSampler("sometimes", 0))},
+		{method: "WithTracingSampler.InvalidRatio", opt: config.WithTracesPipeline(config.WithTracingSampler(config.SamplerTraceIDRatio, 1.1))},
+		{method: "WithTracingSampler.InvalidRate", opt: config.WithTracesPipeline(config.WithTracingSampler(config.SamplerRateLimited, 0))},
+		{method: "WithTracingSamplingRules.InvalidPattern", opt: config.WithTracesPipeline(config.WithTracingSamplingRules(config.SamplingRule{
+			AttributePatterns: map[string]string{"http.target": "("},
+			Sampler:           config.Sampler{Name: config.SamplerAlwaysOff},
+		}))},
+		{method: "WithTracingSamplingRules.MissingSampler", opt: config.WithTracesPipeline(config.WithTracingSamplingRules(config.SamplingRule{
+			SpanName: "GET /healthz",
+		}))},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
+)
+
+// ErrResourceConflict is reported when the resource attributes
+// or schema URLs supplied by the options do not agree
+var ErrResourceConflict = errors.New("resource conflict")
+
+// ResourceSource ranks where resource attributes were supplied from,
+// attributes from a higher source take precedence regardless of the option order
+type ResourceSource int
+
+const (
+	// ResourceSourceDefault are the SDK's default attributes, ie telemetry.sdk.name
+	ResourceSourceDefault ResourceSource = iota
+	// ResourceSourceDetector are attributes from WithResourceDetector and WithResourceDetectors
+	ResourceSourceDetector
+	// ResourceSourceEnvironment are attributes from OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES
+	ResourceSourceEnvironment
+	// ResourceSourceExplicit are attributes from WithServiceName and WithAttributes
+	ResourceSourceExplicit
+)
+
+func (rs ResourceSource) String() string {
+	switch rs {
+	case ResourceSourceDefault:
+		return "default"
+	case ResourceSourceDetector:
+		return "detector"
+	case ResourceSourceEnvironment:
+		return "environment"
+	case ResourceSourceExplicit:
+		return "explicit"
+	}
+	return fmt.Sprintf("ResourceSource(%d)", int(rs))
+}
+
+// ResourceAttribute is a resource attribute and the option that supplied it
+type ResourceAttribute struct {
+	attribute.KeyValue
+	Source ResourceSource
+	// Origin names the option, environment variable or detector
+	Origin string
+}
+
+// ResourceSchemaURL is a schema URL and the option that supplied it
+type ResourceSchemaURL struct {
+	URL    string
+	Source ResourceSource
+	Origin string
+}
+
+// ResourceReport describes how the resource was built
+type ResourceReport struct {
+	// Attributes are the attributes used by the resource sorted by key
+	Attributes []ResourceAttribute
+	// Overridden are the attributes replaced by a different value
+	// from the same or a higher source
+	Overridden []ResourceAttribute
+	// SchemaURL is used by the resource, it is taken from the highest source
+	SchemaURL string
+	// SchemaURLs are the distinct schema URLs supplied,
+	// more than one means the resources did not agree
+	SchemaURLs []ResourceSchemaURL
+}
+
+// Err returns an error wrapping ErrResourceConflict for each overridden attribute
+// and mismatched schema URL, overridden default attributes are not included
+// since they are expected to be replaced.
+func (r ResourceReport) Err() (err error) {
+	used := make(map[attribute.Key]ResourceAttribute, len(r.Attributes))
+	for _, attr := range r.Attributes {
+		used[attr.Key] = attr
+	}
+	for _, o := range r.Overridden {
+		if o.Source == ResourceSourceDefault {
+			continue
+		}
+		u := used[o.Key]
+		err = multierr.Append(err, fmt.Errorf(
+			"%s set to %q by %s overrides %q from %s: %w",
+			o.Key, u.Value.Emit(), u.Origin, o.Value.Emit(), o.Origin, ErrResourceConflict,
+		))
+	}
+	if len(r.SchemaURLs) > 1 {
+		for _, s := range r.SchemaURLs {
+			if s.URL != r.SchemaURL {
+				err = multierr.Append(err, fmt.Errorf(
+					"schema URL %s from %s does not match %s: %w",
+					s.URL, s.Origin, r.SchemaURL, ErrResourceConflict,
+				))
+			}
+		}
+	}
+	return err
+}
+
+// DetectorErr returns the errors from Err where a detector's attribute
+// is overridden by a later detector, ie host.name from the host and ec2 detectors
+func (r ResourceReport) DetectorErr() error {
+	detected := ResourceReport{Attributes: r.Attributes}
+	for _, attr := range r.Attributes {
+		if attr.Source != ResourceSourceDetector {
+			continue
+		}
+		for _, o := range r.Overridden {
+			if o.Key == attr.Key && o.Source == ResourceSourceDetector {
+				detected.Overridden = append(detected.Overridden, o)
+			}
+		}
+	}
+	return detected.Err()
+}
+
+// resourceEntry is a resource added by an option
+type resourceEntry struct {
+	source ResourceSource
+	origin string
+	res    *resource.Resource
+}
+
+func (c *Config) addResource(source ResourceSource, origin string, res *resource.Resource) {
+	c.resources = append(c.resources, resourceEntry{source: source, origin: origin, res: res})
+}
+
+// sortedResources returns the resources from the lowest to the highest source,
+// resources of the same source keep the order they were added
+func (c *Config) sortedResources() []resourceEntry {
+	sorted := append([]resourceEntry(nil), c.resources...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].source < sorted[j].source
+	})
+	return sorted
+}
+
+// ResourceReport describes which option supplied each of the resource attributes
+// and the values that were overridden
+func (c *Config) ResourceReport() ResourceReport {
+	var (
+		report ResourceReport
+		used   = make(map[attribute.Key]ResourceAttribute)
+		urls   = make(map[string]bool)
+	)
+	for _, entry := range c.sortedResources() {
+		for _, kv := range entry.res.Attributes() {
+			attr := ResourceAttribute{KeyValue: kv, Source: entry.source, Origin: entry.origin}
+			if prev, exist := used[kv.Key]; exist && prev.Value != kv.Value {
+				report.Overridden = append(report.Overridden, prev)
+			}
+			used[kv.Key] = attr
+		}
+		if url := entry.res.SchemaURL(); url != "" {
+			report.SchemaURL = url
+			if !urls[url] {
+				urls[url] = true
+				report.SchemaURLs = append(report.SchemaURLs, ResourceSchemaURL{URL: url, Source: entry.source, Origin: entry.origin})
+			}
+		}
+	}
+	for _, attr := range used {
+		report.Attributes = append(report.Attributes, attr)
+	}
+	sort.Slice(report.Attributes, func(i, j int) bool {
+		return report.Attributes[i].Key < report.Attributes[j].Key
+	})
+	return report
+}
+
+// GetResource returns the resource built from the options,
+// attributes are chosen by their source and then by the order the options were applied.
+// Mismatched schema URLs do not cause an error, the highest source's URL is used instead.
+func (c *Config) GetResource() *resource.Resource {
+	report := c.ResourceReport()
+	attrs := make([]attribute.KeyValue, 0, len(report.Attributes))
+	for _, attr := range report.Attributes {
+		attrs = append(attrs, attr.KeyValue)
+	}
+	return resource.NewWithAttributes(report.SchemaURL, attrs...)
+}
+
+// DetectResources runs the detectors named by ResourceDetectors
+// reading from the source set by WithDetectorSource,
+// and adds their attributes to the resource
+func (c *Config) DetectResources(ctx context.Context) error {
+	for _, name := range c.ResourceDetectors {
+		d, err := detectors.New(name, c.detectorSource)
+		if err != nil {
+			return err
+		}
+		res, err := d.Detect(ctx)
+		if err != nil {
+			return fmt.Errorf("%s detector: %w", name, err)
+		}
+		c.addResource(ResourceSourceDetector, name+" detector", res)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
+)
+
+// staticDetector returns a fixed resource
+type staticDetector struct {
+	res *resource.Resource
+}
+
+func (sd staticDetector) Detect(_ context.Context) (*resource.Resource, error) {
+	return sd.res, nil
+}
+
+func TestResourcePrecedence(t *testing.T) {
+	t.Setenv(config.EnvServiceName, "from-env")
+	t.Setenv(config.EnvResourceAttributes, "deployment.environment=staging,team=payments")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.FromEnvironment(),
+		config.WithServiceName("checkout"),
+		// Detectors applied last must not override the explicit or environment attributes
+		config.WithResourceDetector(context.Background(), staticDetector{resource.NewSchemaless(
+			semconv.ServiceNameKey.String("detected"),
+			attribute.String("team", "detected"),
+			attribute.String("region", "us-west-2"),
+		)}),
+		config.WithAttributes(attribute.String("deployment.environment", "production")),
+	))
+
+	attrs := conf.GetResource().Set()
+	for key, expect := range map[attribute.Key]string{
+		semconv.ServiceNameKey:   "checkout",
+		"deployment.environment": "production",
+		"team":                   "payments",
+		"region":                 "us-west-2",
+	} {
+		v, ok := attrs.Value(key)
+		assert.True(t, ok, "Must have the attribute %s", key)
+		assert.Equal(t, expect, v.AsString(), "Must use the highest source for %s", key)
+	}
+}
+
+func TestResourceReport(t *testing.T) {
+	t.Setenv(config.EnvServiceName, "from-env")
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.FromEnvironment(),
+		config.WithResourceDetector(context.Background(), staticDetector{resource.NewSchemaless(
+			semconv.ServiceNameKey.String("detected"),
+			attribute.String("region", "us-west-2"),
+		)}),
+		config.WithServiceName("checkout"),
+	))
+
+	report := conf.ResourceReport()
+
+	used := make(map[attribute.Key]config.ResourceAttribute)
+	for _, attr := range report.Attributes {
+		used[attr.Key] = attr
+	}
+	assert.Equal(t, config.ResourceAttribute{
+		KeyValue: semconv.ServiceNameKey.String("checkout"),
+		Source:   config.ResourceSourceExplicit,
+		Origin:   "WithServiceName",
+	}, used[semconv.ServiceNameKey])
+	assert.Equal(t, config.ResourceAttribute{
+		KeyValue: attribute.String("region", "us-west-2"),
+		Source:   config.ResourceSourceDetector,
+		Origin:   "WithResourceDetector",
+	}, used["region"])
+	assert.Equal(t, config.ResourceSourceDefault, used[semconv.TelemetrySDKNameKey].Source)
+
+	var overridden []string
+	for _, attr := range report.Overridden {
+		if attr.Key == semconv.ServiceNameKey {
+			overridden = append(overridden, attr.Source.String()+"="+attr.Value.AsString())
+		}
+	}
+	assert.Len(t, overridden, 3, "Must record each overridden service name")
+	assert.Contains(t, overridden, "detector=detected")
+	assert.Contains(t, overridden, "environment=from-env")
+
+	err := report.Err()
+	assert.ErrorIs(t, err, config.ErrResourceConflict)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `service.name set to "checkout" by WithServiceName overrides "detected" from WithResourceDetector`)
+		assert.Contains(t, err.Error(), `overrides "from-env" from OTEL_SERVICE_NAME`)
+		assert.NotContains(t, err.Error(), "unknown_service", "Must not report the overridden defaults")
+	}
+
+	assert.NoError(t, config.NewDefault().ResourceReport().Err(), "Must not report conflicts with only the defaults")
+}
+
+func TestResourceSchemaURLConflicts(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.WithResourceDetector(context.Background(), staticDetector{
+			resource.NewWithAttributes("https://opentelemetry.io/schemas/1.4.0", attribute.String("region", "us-west-2")),
+		}),
+	), "Must not error with mismatched schema URLs")
+
+	report := conf.ResourceReport()
+	assert.Len(t, report.SchemaURLs, 2, "Must record the default and detector schema URLs")
+	assert.Equal(t, "https://opentelemetry.io/schemas/1.4.0", report.SchemaURL, "Must use the highest source's schema URL")
+	assert.Equal(t, report.SchemaURL, conf.GetResource().SchemaURL())
+
+	err := report.Err()
+	if assert.ErrorIs(t, err, config.ErrResourceConflict) {
+		assert.Contains(t, err.Error(), "from default does not match https://opentelemetry.io/schemas/1.4.0")
+	}
+}
+
+func TestDetectResources(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.WithResourceDetectors(detectors.ServiceInstance),
+		config.WithAttributes(attribute.String("service.instance.id", "configured")),
+	))
+	require.NoError(t, conf.DetectResources(context.Background()))
+
+	v, ok := conf.GetResource().Set().Value(semconv.ServiceInstanceIDKey)
+	assert.True(t, ok)
+	assert.Equal(t, "configured", v.AsString(), "Must prefer explicit attributes over detected attributes")
+
+	var origins []string
+	for _, attr := range conf.ResourceReport().Overridden {
+		origins = append(origins, attr.Origin)
+	}
+	assert.Equal(t, []string{"service.instance detector"}, origins)
+}
+
+func TestDetectResourcesFromSource(t *testing.T) {
+	t.Parallel()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RequestURI() {
+		case "/computeMetadata/v1/instance/?recursive=true":
+			_, _ = w.Write([]byte(`{"id": 1, "name": "checkout-1", "zone": "zones/us-central1-a"}`))
+		case "/computeMetadata/v1/project/project-id":
+			_, _ = w.Write([]byte("shop-prod"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(
+		config.WithDetectorSource(detectors.Source{
+			FS: fstest.MapFS{},
+			Hostname: func() (string, error) {
+				return "fake-host", nil
+			},
+			GCEEndpoint: s.URL,
+		}),
+		config.WithResourceDetectors(detectors.Host, detectors.GCE),
+		config.WithAttributes(attribute.String("host.id", "configured")),
+	))
+	require.NoError(t, conf.DetectResources(context.Background()))
+
+	v, ok := conf.GetResource().Set().Value(semconv.HostNameKey)
+	assert.True(t, ok)
+	assert.Equal(t, "checkout-1", v.AsString(), "Must use the later detector's value")
+
+	report := conf.ResourceReport()
+	err := report.DetectorErr()
+	if assert.ErrorIs(t, err, config.ErrResourceConflict, "Must report the detectors disagreeing") {
+		assert.Contains(t, err.Error(), `host.name set to "checkout-1" by gce detector overrides "fake-host" from host detector`)
+		assert.NotContains(t, err.Error(), "host.id", "Must not report the attributes overridden by options")
+	}
+	assert.Contains(t, report.Err().Error(), "host.id", "Must report all conflicts")
+}
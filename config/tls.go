@@ -0,0 +1,95 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLS defines the certificates used to connect to an exporter's endpoint,
+// the files are read each time the client configuration is created.
+type TLS struct {
+	// CAFile or CAPEM contain the certificate authorities used to verify the server
+	CAFile string
+	CAPEM  []byte
+
+	// CertFile and KeyFile, or CertPEM and KeyPEM contain
+	// the client certificate used for mutual TLS
+	CertFile string
+	KeyFile  string
+	CertPEM  []byte
+	KeyPEM   []byte
+
+	// ServerName overrides the name used to verify the server's certificate
+	ServerName string
+	// MinVersion is the minimum TLS version accepted, ie tls.VersionTLS12
+	MinVersion uint16
+}
+
+// Enabled returns true when any of the TLS settings have been configured
+func (t TLS) Enabled() bool {
+	return t.CAFile != "" || len(t.CAPEM) != 0 ||
+		t.CertFile != "" || len(t.CertPEM) != 0 ||
+		t.KeyFile != "" || len(t.KeyPEM) != 0 ||
+		t.ServerName != "" || t.MinVersion != 0
+}
+
+// ClientConfig creates the client TLS configuration,
+// nil is returned when no settings have been configured.
+func (t TLS) ClientConfig() (*tls.Config, error) {
+	if !t.Enabled() {
+		return nil, nil
+	}
+
+	conf := &tls.Config{
+		ServerName: t.ServerName,
+		MinVersion: t.MinVersion,
+	}
+
+	ca, err := readPEM(t.CAFile, t.CAPEM)
+	if err != nil {
+		return nil, err
+	}
+	if len(ca) != 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no valid certificate authorities found: %w", ErrInvalidParam)
+		}
+		conf.RootCAs = pool
+	}
+
+	cert, err := readPEM(t.CertFile, t.CertPEM)
+	if err != nil {
+		return nil, err
+	}
+	key, err := readPEM(t.KeyFile, t.KeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if len(cert) != 0 || len(key) != 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v: %w", err, ErrInvalidParam)
+		}
+		conf.Certificates = []tls.Certificate{pair}
+	}
+
+	return conf, nil
+}
+
+func readPEM(path string, pem []byte) ([]byte, error) {
+	if path == "" {
+		return pem, nil
+	}
+	return os.ReadFile(path)
+}
+
+// tlsVersions maps the names accepted by the configuration
+// file and environment onto the TLS versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
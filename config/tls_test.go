@@ -0,0 +1,166 @@
+package config_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+)
+
+// writeCertificate creates a self signed certificate and key,
+// writing the PEM encoded files into dir
+func writeCertificate(t *testing.T, dir string) (certPath, keyPath string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "Must be able to generate a key")
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "otel-go-starter"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err, "Must be able to create a certificate")
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err, "Must be able to marshal the key")
+
+	certPath, keyPath = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certPath, keyPath
+}
+
+func TestExporterTLSOptions(t *testing.T) {
+	t.Parallel()
+
+	certPath, keyPath := writeCertificate(t, t.TempDir())
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.WithTracesPipeline(config.WithTracingExporterOptions(
+		config.WithExporterCACertificate(certPath),
+		config.WithExporterClientCertificate(certPath, keyPath),
+		config.WithExporterServerName("collector.internal"),
+		config.WithExporterMinTLSVersion(tls.VersionTLS12),
+	))), "Must not error with valid certificates")
+
+	tlsConf, err := conf.Tracing.Export.TLS.ClientConfig()
+	require.NoError(t, err, "Must be able to load the certificates")
+	require.NotNil(t, tlsConf, "Must create a tls configuration")
+	assert.NotNil(t, tlsConf.RootCAs, "Must use the provided certificate authority")
+	assert.Len(t, tlsConf.Certificates, 1, "Must use the client certificate")
+	assert.Equal(t, "collector.internal", tlsConf.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), tlsConf.MinVersion)
+
+	tlsConf, err = config.NewDefaultExport().TLS.ClientConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConf, "Must not create a tls configuration when no settings are provided")
+}
+
+func TestInvalidExporterTLSOptions(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certPath, keyPath := writeCertificate(t, dir)
+	garbage := filepath.Join(dir, "garbage.pem")
+	require.NoError(t, os.WriteFile(garbage, []byte("not a certificate"), 0o600))
+
+	testCases := []struct {
+		scenario string
+		opt      config.ExportOption
+	}{
+		{scenario: "Missing CA file", opt: config.WithExporterCACertificate(filepath.Join(dir, "missing.pem"))},
+		{scenario: "Invalid CA file", opt: config.WithExporterCACertificate(garbage)},
+		{scenario: "Missing client key", opt: config.WithExporterClientCertificate(certPath, "")},
+		{scenario: "Mismatched client key", opt: config.WithExporterClientCertificate(certPath, garbage)},
+		{scenario: "Swapped client certificate", opt: config.WithExporterClientCertificate(keyPath, certPath)},
+		{scenario: "Empty server name", opt: config.WithExporterServerName("")},
+		{scenario: "Unknown TLS version", opt: config.WithExporterMinTLSVersion(0x0200)},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			conf := config.NewDefault()
+			err := conf.Apply(config.WithMetricsPipeline(config.WithMetricsExporterOptions(tc.opt)))
+			assert.ErrorIs(t, err, config.ErrInvalidParam, "Must validate the certificates when configured")
+			assert.False(t, conf.Metrics.Export.TLS.Enabled(), "Must not apply invalid settings")
+		})
+	}
+}
+
+func TestExporterTLSFromFile(t *testing.T) {
+	t.Parallel()
+
+	certPath, keyPath := writeCertificate(t, t.TempDir())
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromReader(strings.NewReader(`
+tracing:
+  export:
+    name: otlpgrpc
+    tls:
+      ca_file: `+certPath+`
+      cert_file: `+certPath+`
+      key_file: `+keyPath+`
+      server_name: collector.internal
+      min_version: "1.3"
+`))), "Must not error with valid tls settings")
+
+	assert.Equal(t, certPath, conf.Tracing.Export.TLS.CAFile)
+	assert.Equal(t, keyPath, conf.Tracing.Export.TLS.KeyFile)
+	assert.Equal(t, "collector.internal", conf.Tracing.Export.TLS.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS13), conf.Tracing.Export.TLS.MinVersion)
+
+	err := config.NewDefault().Apply(config.FromReader(strings.NewReader(`
+tracing:
+  export:
+    tls:
+      min_version: "2.0"
+`)))
+	if assert.ErrorIs(t, err, config.ErrInvalidParam) {
+		assert.Contains(t, err.Error(), "line 5: tracing.export.tls.min_version")
+	}
+}
+
+func TestExporterTLSFromEnvironment(t *testing.T) {
+	certPath, keyPath := writeCertificate(t, t.TempDir())
+
+	t.Setenv(config.EnvTracesExporter, "otlp")
+	t.Setenv(config.EnvExporterOTLPCertificate, certPath)
+	t.Setenv(signalVariable(config.EnvExporterOTLPClientCertificate), certPath)
+	t.Setenv(signalVariable(config.EnvExporterOTLPClientKey), keyPath)
+
+	conf := config.NewDefault()
+	require.NoError(t, conf.Apply(config.FromEnvironment()), "Must not error with valid certificates")
+
+	assert.Equal(t, certPath, conf.Tracing.Export.TLS.CAFile)
+	assert.Equal(t, certPath, conf.Tracing.Export.TLS.CertFile)
+	assert.Equal(t, keyPath, conf.Tracing.Export.TLS.KeyFile)
+}
+
+// signalVariable returns the traces specific variant of the OTLP variable
+func signalVariable(key string) string {
+	return strings.Replace(key, "OTEL_EXPORTER_OTLP_", "OTEL_EXPORTER_OTLP_TRACES_", 1)
+}
@@ -0,0 +1,247 @@
+package detectors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+const (
+	ec2Endpoint = "http://169.254.169.254"
+	gceEndpoint = "http://metadata.google.internal"
+
+	// EnvECSMetadataURI is set by the ECS agent within each container
+	EnvECSMetadataURI = "ECS_CONTAINER_METADATA_URI_V4"
+
+	// metadataTimeout is kept short since the metadata
+	// services are not reachable outside of the cloud provider
+	metadataTimeout = time.Second
+)
+
+// metadataClient does not use the proxy variables
+// since the metadata services are only reachable from the instance
+var metadataClient = &http.Client{Transport: &http.Transport{
+	DialContext: (&net.Dialer{Timeout: metadataTimeout}).DialContext,
+}}
+
+// errUnreachable is returned when the metadata service could not be reached,
+// meaning the detector is not running on the cloud provider
+var errUnreachable = errors.New("metadata service unreachable")
+
+// getMetadata requests the metadata at base joined with path
+func getMetadata(ctx context.Context, src Source, method, base, path string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(base, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := src.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, errUnreachable)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s returned %s", method, req.URL.Path, resp.Status)
+	}
+	return body, nil
+}
+
+// cloudDetector limits the detector to the metadata timeout and treats an
+// unreachable metadata service as not running on the cloud provider
+func cloudDetector(ctx context.Context, src Source, detect detectFunc) ([]attribute.KeyValue, error) {
+	ctx, cancel := context.WithTimeout(ctx, src.MetadataTimeout)
+	defer cancel()
+
+	attrs, err := detect(ctx, src)
+	if errors.Is(err, errUnreachable) {
+		return nil, nil
+	}
+	return attrs, err
+}
+
+// ec2Identity is the instance identity document
+type ec2Identity struct {
+	AccountID        string `json:"accountId"`
+	AvailabilityZone string `json:"availabilityZone"`
+	ImageID          string `json:"imageId"`
+	InstanceID       string `json:"instanceId"`
+	InstanceType     string `json:"instanceType"`
+	Region           string `json:"region"`
+}
+
+func detectEC2(ctx context.Context, src Source) ([]attribute.KeyValue, error) {
+	return cloudDetector(ctx, src, func(ctx context.Context, src Source) ([]attribute.KeyValue, error) {
+		header := http.Header{}
+		// IMDSv2 requires a session token, instances that only allow
+		// IMDSv1 reject the request so the token is not required
+		token, err := getMetadata(ctx, src, http.MethodPut, src.EC2Endpoint, "/latest/api/token", http.Header{
+			"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {"60"},
+		})
+		if errors.Is(err, errUnreachable) {
+			return nil, err
+		}
+		if err == nil {
+			header.Set("X-Aws-Ec2-Metadata-Token", string(token))
+		}
+
+		doc, err := getMetadata(ctx, src, http.MethodGet, src.EC2Endpoint, "/latest/dynamic/instance-identity/document", header)
+		if err != nil {
+			return nil, err
+		}
+		var id ec2Identity
+		if err := json.Unmarshal(doc, &id); err != nil {
+			return nil, fmt.Errorf("invalid instance identity document: %w", err)
+		}
+
+		attrs := []attribute.KeyValue{
+			semconv.CloudProviderAWS,
+			semconv.CloudPlatformAWSEC2,
+			semconv.CloudRegionKey.String(id.Region),
+			semconv.CloudAvailabilityZoneKey.String(id.AvailabilityZone),
+			semconv.CloudAccountIDKey.String(id.AccountID),
+			semconv.HostIDKey.String(id.InstanceID),
+			semconv.HostTypeKey.String(id.InstanceType),
+			semconv.HostImageIDKey.String(id.ImageID),
+		}
+		if hostname, err := getMetadata(ctx, src, http.MethodGet, src.EC2Endpoint, "/latest/meta-data/hostname", header); err == nil {
+			attrs = append(attrs, semconv.HostNameKey.String(string(hostname)))
+		}
+		return attrs, nil
+	})
+}
+
+// ecsTask is the task metadata returned by the v4 endpoint
+type ecsTask struct {
+	Cluster          string `json:"Cluster"`
+	TaskARN          string `json:"TaskARN"`
+	Family           string `json:"Family"`
+	Revision         string `json:"Revision"`
+	AvailabilityZone string `json:"AvailabilityZone"`
+	LaunchType       string `json:"LaunchType"`
+}
+
+// ecsContainer is the container metadata returned by the v4 endpoint
+type ecsContainer struct {
+	DockerID     string `json:"DockerId"`
+	Name         string `json:"Name"`
+	ContainerARN string `json:"ContainerARN"`
+}
+
+func detectECS(ctx context.Context, src Source) ([]attribute.KeyValue, error) {
+	base, ok := src.LookupEnv(EnvECSMetadataURI)
+	if !ok || base == "" {
+		return nil, nil
+	}
+	return cloudDetector(ctx, src, func(ctx context.Context, src Source) ([]attribute.KeyValue, error) {
+		var (
+			task      ecsTask
+			container ecsContainer
+		)
+		for _, metadata := range []struct {
+			path  string
+			value interface{}
+		}{
+			{path: "/task", value: &task},
+			{path: "", value: &container},
+		} {
+			body, err := getMetadata(ctx, src, http.MethodGet, base, metadata.path, nil)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(body, metadata.value); err != nil {
+				return nil, fmt.Errorf("invalid task metadata: %w", err)
+			}
+		}
+
+		attrs := []attribute.KeyValue{
+			semconv.CloudProviderAWS,
+			semconv.CloudPlatformAWSECS,
+			semconv.AWSECSTaskARNKey.String(task.TaskARN),
+			semconv.AWSECSTaskFamilyKey.String(task.Family),
+			semconv.AWSECSTaskRevisionKey.String(task.Revision),
+			semconv.AWSECSLaunchtypeKey.String(strings.ToLower(task.LaunchType)),
+			semconv.ContainerIDKey.String(container.DockerID),
+			semconv.ContainerNameKey.String(container.Name),
+		}
+		if container.ContainerARN != "" {
+			attrs = append(attrs, semconv.AWSECSContainerARNKey.String(container.ContainerARN))
+		}
+		if task.AvailabilityZone != "" {
+			attrs = append(attrs, semconv.CloudAvailabilityZoneKey.String(task.AvailabilityZone))
+		}
+		// arn:aws:ecs:<region>:<account>:task/<cluster>/<id>
+		if arn := strings.SplitN(task.TaskARN, ":", 6); len(arn) == 6 {
+			attrs = append(attrs,
+				semconv.CloudRegionKey.String(arn[3]),
+				semconv.CloudAccountIDKey.String(arn[4]),
+			)
+			cluster := task.Cluster
+			if !strings.HasPrefix(cluster, "arn:") {
+				cluster = strings.Join(append(arn[:5:5], "cluster/"+cluster), ":")
+			}
+			attrs = append(attrs, semconv.AWSECSClusterARNKey.String(cluster))
+		}
+		return attrs, nil
+	})
+}
+
+// gceInstance is the recursive instance metadata
+type gceInstance struct {
+	ID          json.Number `json:"id"`
+	Name        string      `json:"name"`
+	MachineType string      `json:"machineType"`
+	Zone        string      `json:"zone"`
+}
+
+func detectGCE(ctx context.Context, src Source) ([]attribute.KeyValue, error) {
+	return cloudDetector(ctx, src, func(ctx context.Context, src Source) ([]attribute.KeyValue, error) {
+		header := http.Header{"Metadata-Flavor": {"Google"}}
+
+		body, err := getMetadata(ctx, src, http.MethodGet, src.GCEEndpoint, "/computeMetadata/v1/instance/?recursive=true", header)
+		if err != nil {
+			return nil, err
+		}
+		var instance gceInstance
+		if err := json.Unmarshal(body, &instance); err != nil {
+			return nil, fmt.Errorf("invalid instance metadata: %w", err)
+		}
+		project, err := getMetadata(ctx, src, http.MethodGet, src.GCEEndpoint, "/computeMetadata/v1/project/project-id", header)
+		if err != nil {
+			return nil, err
+		}
+
+		// The zone and machine type are returned as resource paths,
+		// ie projects/<number>/zones/us-central1-a
+		zone := path.Base(instance.Zone)
+		attrs := []attribute.KeyValue{
+			semconv.CloudProviderGCP,
+			semconv.CloudPlatformGCPComputeEngine,
+			semconv.CloudAccountIDKey.String(string(project)),
+			semconv.CloudAvailabilityZoneKey.String(zone),
+			semconv.HostIDKey.String(instance.ID.String()),
+			semconv.HostNameKey.String(instance.Name),
+			semconv.HostTypeKey.String(path.Base(instance.MachineType)),
+		}
+		if i := strings.LastIndex(zone, "-"); i > 0 {
+			attrs = append(attrs, semconv.CloudRegionKey.String(zone[:i]))
+		}
+		return attrs, nil
+	})
+}
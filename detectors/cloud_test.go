@@ -0,0 +1,211 @@
+package detectors_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
+)
+
+// metadataServer serves the responses by path,
+// requests missing the header are rejected
+func metadataServer(t *testing.T, header, value string, responses map[string]string) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if header != "" && r.Header.Get(header) != value {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := responses[r.Method+" "+r.URL.RequestURI()]
+		if !ok {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = rw.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestEC2Detector(t *testing.T) {
+	t.Parallel()
+
+	var tokens int
+	imds := metadataServer(t, "", "", map[string]string{
+		"GET /latest/dynamic/instance-identity/document": `{
+			"accountId": "123456789012",
+			"availabilityZone": "us-west-2b",
+			"imageId": "ami-5fb8c835",
+			"instanceId": "i-1234567890abcdef0",
+			"instanceType": "t2.micro",
+			"region": "us-west-2"
+		}`,
+		"GET /latest/meta-data/hostname": "ip-10-0-0-1.us-west-2.compute.internal",
+	})
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/latest/api/token" {
+			tokens++
+			_, _ = rw.Write([]byte("session-token"))
+			return
+		}
+		if r.Header.Get("X-Aws-Ec2-Metadata-Token") != "session-token" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		imds.Config.Handler.ServeHTTP(rw, r)
+	}))
+	t.Cleanup(s.Close)
+
+	src := fakeSource(nil, nil)
+	src.EC2Endpoint = s.URL
+
+	res := detect(t, detectors.EC2, src)
+	assert.Equal(t, 1, tokens, "Must request an IMDSv2 session token")
+	assert.Equal(t, []attribute.KeyValue{
+		semconv.CloudAccountIDKey.String("123456789012"),
+		semconv.CloudAvailabilityZoneKey.String("us-west-2b"),
+		semconv.CloudPlatformAWSEC2,
+		semconv.CloudProviderAWS,
+		semconv.CloudRegionKey.String("us-west-2"),
+		semconv.HostIDKey.String("i-1234567890abcdef0"),
+		semconv.HostImageIDKey.String("ami-5fb8c835"),
+		semconv.HostNameKey.String("ip-10-0-0-1.us-west-2.compute.internal"),
+		semconv.HostTypeKey.String("t2.micro"),
+	}, res.Attributes())
+}
+
+func TestECSDetector(t *testing.T) {
+	t.Parallel()
+
+	s := metadataServer(t, "", "", map[string]string{
+		"GET /v4/abc/task": `{
+			"Cluster": "default",
+			"TaskARN": "arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c",
+			"Family": "checkout",
+			"Revision": "7",
+			"AvailabilityZone": "us-west-2a",
+			"LaunchType": "FARGATE"
+		}`,
+		"GET /v4/abc": `{
+			"DockerId": "cd189a933e5849daa93386466019ab50-2495160603",
+			"Name": "app",
+			"ContainerARN": "arn:aws:ecs:us-west-2:111122223333:container/05966557-f16c-49cb-9352-24b3a0dcd0e1"
+		}`,
+	})
+
+	src := fakeSource(nil, map[string]string{detectors.EnvECSMetadataURI: s.URL + "/v4/abc"})
+	res := detect(t, detectors.ECS, src)
+	assert.Equal(t, []attribute.KeyValue{
+		semconv.AWSECSClusterARNKey.String("arn:aws:ecs:us-west-2:111122223333:cluster/default"),
+		semconv.AWSECSContainerARNKey.String("arn:aws:ecs:us-west-2:111122223333:container/05966557-f16c-49cb-9352-24b3a0dcd0e1"),
+		semconv.AWSECSLaunchtypeKey.String("fargate"),
+		semconv.AWSECSTaskARNKey.String("arn:aws:ecs:us-west-2:111122223333:task/default/158d1c8083dd49d6b527399fd6414f5c"),
+		semconv.AWSECSTaskFamilyKey.String("checkout"),
+		semconv.AWSECSTaskRevisionKey.String("7"),
+		semconv.CloudAccountIDKey.String("111122223333"),
+		semconv.CloudAvailabilityZoneKey.String("us-west-2a"),
+		semconv.CloudPlatformAWSECS,
+		semconv.CloudProviderAWS,
+		semconv.CloudRegionKey.String("us-west-2"),
+		semconv.ContainerIDKey.String("cd189a933e5849daa93386466019ab50-2495160603"),
+		semconv.ContainerNameKey.String("app"),
+	}, res.Attributes())
+
+	res = detect(t, detectors.ECS, fakeSource(nil, nil))
+	assert.Equal(t, 0, res.Len(), "Must not detect a task without the metadata variable")
+}
+
+func TestGCEDetector(t *testing.T) {
+	t.Parallel()
+
+	s := metadataServer(t, "Metadata-Flavor", "Google", map[string]string{
+		"GET /computeMetadata/v1/instance/?recursive=true": `{
+			"id": 4520031799277581759,
+			"name": "checkout-1",
+			"machineType": "projects/123456789/machineTypes/e2-medium",
+			"zone": "projects/123456789/zones/us-central1-a"
+		}`,
+		"GET /computeMetadata/v1/project/project-id": "shop-prod",
+	})
+
+	src := fakeSource(nil, nil)
+	src.GCEEndpoint = s.URL
+
+	res := detect(t, detectors.GCE, src)
+	assert.Equal(t, []attribute.KeyValue{
+		semconv.CloudAccountIDKey.String("shop-prod"),
+		semconv.CloudAvailabilityZoneKey.String("us-central1-a"),
+		semconv.CloudPlatformGCPComputeEngine,
+		semconv.CloudProviderGCP,
+		semconv.CloudRegionKey.String("us-central1"),
+		semconv.HostIDKey.String("4520031799277581759"),
+		semconv.HostNameKey.String("checkout-1"),
+		semconv.HostTypeKey.String("e2-medium"),
+	}, res.Attributes())
+}
+
+func TestCloudDetectorsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	t.Cleanup(slow.Close)
+
+	broken := metadataServer(t, "", "", map[string]string{
+		"PUT /latest/api/token":                          "token",
+		"GET /latest/dynamic/instance-identity/document": "<html>",
+	})
+
+	testCases := []struct {
+		scenario string
+		endpoint string
+		err      bool
+	}{
+		{scenario: "Unreachable metadata service", endpoint: closed.URL},
+		{scenario: "Slow metadata service", endpoint: slow.URL},
+		{scenario: "Invalid metadata", endpoint: broken.URL, err: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			src := fakeSource(nil, nil)
+			src.EC2Endpoint, src.GCEEndpoint = tc.endpoint, tc.endpoint
+			src.MetadataTimeout = 50 * time.Millisecond
+
+			for _, name := range []string{detectors.EC2, detectors.GCE} {
+				d, err := detectors.New(name, src)
+				require.NoError(t, err)
+
+				start := time.Now()
+				res, err := d.Detect(context.Background())
+				assert.Less(t, time.Since(start), 500*time.Millisecond, "Must give up after the metadata timeout")
+				if tc.err {
+					assert.Error(t, err, "Must report invalid metadata")
+					continue
+				}
+				if assert.NoError(t, err, "Must not error when not running on the cloud provider") {
+					assert.Equal(t, 0, res.Len())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package detectors
+
+import (
+	"bufio"
+	"context"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+const (
+	// cgroupPath lists the control groups of the process,
+	// the container runtimes include the container ID within the path
+	cgroupPath = "proc/self/cgroup"
+	// mountInfoPath is used for cgroup v2 hosts where the
+	// cgroup path no longer includes the container ID
+	mountInfoPath = "proc/self/mountinfo"
+)
+
+var containerID = regexp.MustCompile(`[0-9a-f]{64}`)
+
+func detectContainer(_ context.Context, src Source) ([]attribute.KeyValue, error) {
+	cgroup, err := readFile(src.FS, cgroupPath)
+	if err != nil {
+		return nil, err
+	}
+	id := cgroupContainerID(cgroup)
+	if id == "" {
+		mounts, err := readFile(src.FS, mountInfoPath)
+		if err != nil {
+			return nil, err
+		}
+		id = mountContainerID(mounts)
+	}
+	if id == "" {
+		return nil, nil
+	}
+	return []attribute.KeyValue{semconv.ContainerIDKey.String(id)}, nil
+}
+
+// cgroupContainerID finds the container ID within the last
+// segment of a cgroup path, ie `1:name=systemd:/docker/<id>` or
+// `0::/system.slice/cri-containerd-<id>.scope`
+func cgroupContainerID(cgroup string) string {
+	s := bufio.NewScanner(strings.NewReader(cgroup))
+	for s.Scan() {
+		fields := strings.SplitN(s.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		segment := fields[2][strings.LastIndex(fields[2], "/")+1:]
+		if id := containerID.FindString(segment); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// mountContainerID finds the container ID from the container runtime's
+// mounts, ie `/var/lib/docker/containers/<id>/hostname`
+func mountContainerID(mounts string) string {
+	s := bufio.NewScanner(strings.NewReader(mounts))
+	for s.Scan() {
+		for _, field := range strings.Fields(s.Text()) {
+			i := strings.Index(field, "/containers/")
+			if i < 0 {
+				continue
+			}
+			rest := field[i+len("/containers/"):]
+			if id := containerID.FindString(rest); id != "" && strings.HasPrefix(rest, id) {
+				return id
+			}
+		}
+	}
+	return ""
+}
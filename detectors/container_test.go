@@ -0,0 +1,75 @@
+package detectors_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
+)
+
+func TestContainerDetector(t *testing.T) {
+	t.Parallel()
+
+	const id = "0f2b6c9e8d7a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c"
+
+	testCases := []struct {
+		scenario string
+		files    fstest.MapFS
+		expect   string
+	}{
+		{
+			scenario: "Docker cgroup v1",
+			files: fstest.MapFS{"proc/self/cgroup": {Data: []byte(
+				"12:pids:/docker/" + id + "\n" +
+					"1:name=systemd:/docker/" + id + "\n",
+			)}},
+			expect: id,
+		},
+		{
+			scenario: "Containerd systemd scope",
+			files: fstest.MapFS{"proc/self/cgroup": {Data: []byte(
+				"0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + id + ".scope\n",
+			)}},
+			expect: id,
+		},
+		{
+			scenario: "Docker cgroup v2",
+			files: fstest.MapFS{
+				"proc/self/cgroup": {Data: []byte("0::/\n")},
+				"proc/self/mountinfo": {Data: []byte(
+					"1 0 0:1 / / rw - overlay overlay rw\n" +
+						"2 1 8:1 /var/lib/docker/containers/" + id + "/hostname /etc/hostname rw - ext4 /dev/sda1 rw\n",
+				)},
+			},
+			expect: id,
+		},
+		{
+			scenario: "Not within a container",
+			files: fstest.MapFS{
+				"proc/self/cgroup":    {Data: []byte("0::/user.slice/user-1000.slice/session-1.scope\n")},
+				"proc/self/mountinfo": {Data: []byte("1 0 0:1 / / rw - ext4 /dev/sda1 rw\n")},
+			},
+		},
+		{
+			scenario: "Missing proc filesystem",
+			files:    fstest.MapFS{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			res := detect(t, detectors.Container, fakeSource(tc.files, nil))
+			if tc.expect == "" {
+				assert.Equal(t, 0, res.Len(), "Must not detect a container")
+				return
+			}
+			assert.Equal(t, tc.expect, value(t, res, semconv.ContainerIDKey).AsString())
+		})
+	}
+}
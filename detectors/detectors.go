@@ -0,0 +1,149 @@
+// Package detectors provides the built in resource detectors
+// that can be selected by name using config.WithResourceDetectors.
+package detectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// Names of the built in detectors
+const (
+	Host            = "host"
+	OS              = "os"
+	Process         = "process"
+	Runtime         = "runtime"
+	Container       = "container"
+	Kubernetes      = "k8s"
+	ServiceInstance = "service.instance"
+
+	// The cloud detectors request the instance's metadata
+	// so they are only run when selected
+	EC2 = "ec2"
+	ECS = "ecs"
+	GCE = "gce"
+)
+
+// ErrUnknownDetector is returned when a detector name is not one of the built in detectors
+var ErrUnknownDetector = errors.New("unknown resource detector")
+
+// Source is what the detectors read from,
+// replacing its fields allows the detectors to be tested against a fake host.
+// Zero values use the host's filesystem, environment and hostname.
+type Source struct {
+	// FS is the root of the filesystem, ie os.DirFS("/")
+	FS fs.FS
+	// LookupEnv reads environment variables, ie os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+	// Hostname returns the host's name, ie os.Hostname
+	Hostname func() (string, error)
+
+	// EC2Endpoint and GCEEndpoint are the base URLs of the instance metadata services,
+	// the ECS endpoint is read from ECS_CONTAINER_METADATA_URI_V4 using LookupEnv
+	EC2Endpoint string
+	GCEEndpoint string
+	// Client is used to request the instance metadata
+	Client *http.Client
+	// MetadataTimeout limits how long each cloud detector is able to take
+	MetadataTimeout time.Duration
+}
+
+func (s Source) withDefaults() Source {
+	if s.FS == nil {
+		s.FS = os.DirFS("/")
+	}
+	if s.LookupEnv == nil {
+		s.LookupEnv = os.LookupEnv
+	}
+	if s.Hostname == nil {
+		s.Hostname = os.Hostname
+	}
+	if s.EC2Endpoint == "" {
+		s.EC2Endpoint = ec2Endpoint
+	}
+	if s.GCEEndpoint == "" {
+		s.GCEEndpoint = gceEndpoint
+	}
+	if s.Client == nil {
+		s.Client = metadataClient
+	}
+	if s.MetadataTimeout <= 0 {
+		s.MetadataTimeout = metadataTimeout
+	}
+	return s
+}
+
+// detectFunc returns the attributes found by a detector,
+// an empty result is returned when the detector does not apply to the host
+type detectFunc func(ctx context.Context, src Source) ([]attribute.KeyValue, error)
+
+var builtin = map[string]detectFunc{
+	Host:            detectHost,
+	OS:              detectOS,
+	Process:         detectProcess,
+	Runtime:         detectRuntime,
+	Container:       detectContainer,
+	Kubernetes:      detectKubernetes,
+	ServiceInstance: detectServiceInstance,
+	EC2:             detectEC2,
+	ECS:             detectECS,
+	GCE:             detectGCE,
+}
+
+// Names returns the sorted names of the built in detectors
+func Names() []string {
+	names := make([]string, 0, len(builtin))
+	for name := range builtin {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// detector adapts a detectFunc to the SDK's resource.Detector
+type detector struct {
+	src    Source
+	detect detectFunc
+}
+
+var _ resource.Detector = (*detector)(nil)
+
+func (d *detector) Detect(ctx context.Context) (*resource.Resource, error) {
+	attrs, err := d.detect(ctx, d.src)
+	if err != nil {
+		return nil, err
+	}
+	return resource.NewSchemaless(attrs...), nil
+}
+
+// New returns the named detector reading from src
+func New(name string, src Source) (resource.Detector, error) {
+	fn, ok := builtin[name]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", name, ErrUnknownDetector)
+	}
+	return &detector{src: src.withDefaults(), detect: fn}, nil
+}
+
+// readFile returns the trimmed contents of the file,
+// missing files are not treated as an error
+func readFile(fsys fs.FS, name string) (string, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
@@ -0,0 +1,122 @@
+package detectors_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"regexp"
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
+)
+
+// fakeSource returns a source that does not read from the host
+func fakeSource(files fstest.MapFS, env map[string]string) detectors.Source {
+	return detectors.Source{
+		FS: files,
+		LookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+		Hostname: func() (string, error) {
+			return "fake-host", nil
+		},
+	}
+}
+
+func detect(t *testing.T, name string, src detectors.Source) *resource.Resource {
+	t.Helper()
+
+	d, err := detectors.New(name, src)
+	require.NoError(t, err, "Must be a built in detector")
+	res, err := d.Detect(context.Background())
+	require.NoError(t, err, "Must not error when detecting")
+	return res
+}
+
+func value(t *testing.T, res *resource.Resource, key attribute.Key) attribute.Value {
+	t.Helper()
+
+	v, ok := res.Set().Value(key)
+	assert.True(t, ok, "Must have detected %s", key)
+	return v
+}
+
+func TestNames(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{
+		detectors.Container,
+		detectors.EC2,
+		detectors.ECS,
+		detectors.GCE,
+		detectors.Host,
+		detectors.Kubernetes,
+		detectors.OS,
+		detectors.Process,
+		detectors.Runtime,
+		detectors.ServiceInstance,
+	}, detectors.Names())
+
+	_, err := detectors.New("ec2-but-misspelt", detectors.Source{})
+	assert.ErrorIs(t, err, detectors.ErrUnknownDetector)
+}
+
+func TestHostDetector(t *testing.T) {
+	t.Parallel()
+
+	res := detect(t, detectors.Host, fakeSource(nil, nil))
+	assert.Equal(t, "fake-host", value(t, res, semconv.HostNameKey).AsString())
+	assert.Equal(t, runtime.GOARCH, value(t, res, semconv.HostArchKey).AsString())
+
+	failing := fakeSource(nil, nil)
+	failing.Hostname = func() (string, error) { return "", errors.New("no hostname") }
+	d, err := detectors.New(detectors.Host, failing)
+	require.NoError(t, err)
+	_, err = d.Detect(context.Background())
+	assert.Error(t, err, "Must return the hostname error")
+}
+
+func TestOSDetector(t *testing.T) {
+	t.Parallel()
+
+	res := detect(t, detectors.OS, fakeSource(fstest.MapFS{
+		"etc/os-release": {Data: []byte("NAME=\"Alpine Linux\"\nID=alpine\nPRETTY_NAME=\"Alpine Linux v3.15\"\n")},
+	}, nil))
+	assert.Equal(t, runtime.GOOS, value(t, res, semconv.OSTypeKey).AsString())
+	assert.Equal(t, "Alpine Linux v3.15", value(t, res, semconv.OSDescriptionKey).AsString())
+
+	res = detect(t, detectors.OS, fakeSource(fstest.MapFS{}, nil))
+	_, ok := res.Set().Value(semconv.OSDescriptionKey)
+	assert.False(t, ok, "Must not set the description without an os-release file")
+}
+
+func TestProcessDetectors(t *testing.T) {
+	t.Parallel()
+
+	res := detect(t, detectors.Process, detectors.Source{})
+	assert.Equal(t, int64(os.Getpid()), value(t, res, semconv.ProcessPIDKey).AsInt64())
+	assert.Equal(t, os.Args, value(t, res, semconv.ProcessCommandArgsKey).AsStringSlice())
+
+	res = detect(t, detectors.Runtime, detectors.Source{})
+	assert.Equal(t, runtime.Version(), value(t, res, semconv.ProcessRuntimeVersionKey).AsString())
+}
+
+func TestServiceInstanceDetector(t *testing.T) {
+	t.Parallel()
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first := value(t, detect(t, detectors.ServiceInstance, detectors.Source{}), semconv.ServiceInstanceIDKey).AsString()
+	second := value(t, detect(t, detectors.ServiceInstance, detectors.Source{}), semconv.ServiceInstanceIDKey).AsString()
+	assert.Regexp(t, uuid, first, "Must be a version 4 UUID")
+	assert.NotEqual(t, first, second, "Must generate a new ID each time")
+}
@@ -0,0 +1,102 @@
+package detectors
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// osReleasePath is read for the operating system's description
+const osReleasePath = "etc/os-release"
+
+func detectHost(_ context.Context, src Source) ([]attribute.KeyValue, error) {
+	name, err := src.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	return []attribute.KeyValue{
+		semconv.HostNameKey.String(name),
+		semconv.HostArchKey.String(runtime.GOARCH),
+	}, nil
+}
+
+func detectOS(_ context.Context, src Source) ([]attribute.KeyValue, error) {
+	attrs := []attribute.KeyValue{semconv.OSTypeKey.String(runtime.GOOS)}
+
+	release, err := readFile(src.FS, osReleasePath)
+	if err != nil {
+		return nil, err
+	}
+	if desc := osReleaseValue(release, "PRETTY_NAME"); desc != "" {
+		attrs = append(attrs, semconv.OSDescriptionKey.String(desc))
+	}
+	return attrs, nil
+}
+
+// osReleaseValue returns the unquoted value of key from an os-release file
+func osReleaseValue(release, key string) string {
+	s := bufio.NewScanner(strings.NewReader(release))
+	for s.Scan() {
+		kv := strings.SplitN(s.Text(), "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) != key {
+			continue
+		}
+		v := strings.TrimSpace(kv[1])
+		if unquoted, err := strconv.Unquote(v); err == nil {
+			return unquoted
+		}
+		return strings.Trim(v, `'`)
+	}
+	return ""
+}
+
+func detectProcess(_ context.Context, _ Source) ([]attribute.KeyValue, error) {
+	attrs := []attribute.KeyValue{
+		semconv.ProcessPIDKey.Int(os.Getpid()),
+		semconv.ProcessCommandArgsKey.StringSlice(os.Args),
+	}
+	if len(os.Args) > 0 {
+		attrs = append(attrs, semconv.ProcessExecutableNameKey.String(filepath.Base(os.Args[0])))
+	}
+	if path, err := os.Executable(); err == nil {
+		attrs = append(attrs, semconv.ProcessExecutablePathKey.String(path))
+	}
+	// The owner is not always known within containers
+	// that run as a user without an entry in /etc/passwd
+	if u, err := user.Current(); err == nil {
+		attrs = append(attrs, semconv.ProcessOwnerKey.String(u.Username))
+	}
+	return attrs, nil
+}
+
+func detectRuntime(_ context.Context, _ Source) ([]attribute.KeyValue, error) {
+	return []attribute.KeyValue{
+		semconv.ProcessRuntimeNameKey.String(runtime.Compiler),
+		semconv.ProcessRuntimeVersionKey.String(runtime.Version()),
+		semconv.ProcessRuntimeDescriptionKey.String(fmt.Sprintf("go version %s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH)),
+	}, nil
+}
+
+// detectServiceInstance generates a random version 4 UUID
+// to identify this instance of the service
+func detectServiceInstance(_ context.Context, _ Source) ([]attribute.KeyValue, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return []attribute.KeyValue{
+		semconv.ServiceInstanceIDKey.String(fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])),
+	}, nil
+}
@@ -0,0 +1,85 @@
+package detectors
+
+import (
+	"context"
+	"path"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// Environment variables set using the Kubernetes downward API
+const (
+	EnvPodName       = "K8S_POD_NAME"
+	EnvPodUID        = "K8S_POD_UID"
+	EnvNamespaceName = "K8S_NAMESPACE_NAME"
+	EnvNodeName      = "K8S_NODE_NAME"
+	EnvContainerName = "K8S_CONTAINER_NAME"
+
+	// envServiceHost is set on every container running within Kubernetes
+	envServiceHost = "KUBERNETES_SERVICE_HOST"
+)
+
+const (
+	// PodInfoPath is where the downward API volume is expected to be mounted,
+	// the files name, namespace and uid are read when the variables are not set
+	PodInfoPath = "etc/podinfo"
+
+	serviceAccountNamespacePath = "var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+func detectKubernetes(_ context.Context, src Source) ([]attribute.KeyValue, error) {
+	var (
+		attrs []attribute.KeyValue
+		err   error
+	)
+	// lookup returns the first of the variable or files that is set
+	lookup := func(key attribute.Key, env string, files ...string) {
+		if err != nil {
+			return
+		}
+		if v, ok := src.LookupEnv(env); ok && v != "" {
+			attrs = append(attrs, key.String(v))
+			return
+		}
+		for _, file := range files {
+			v, ferr := readFile(src.FS, file)
+			if ferr != nil {
+				err = ferr
+				return
+			}
+			if v != "" {
+				attrs = append(attrs, key.String(v))
+				return
+			}
+		}
+	}
+
+	lookup(semconv.K8SPodNameKey, EnvPodName, path.Join(PodInfoPath, "name"))
+	lookup(semconv.K8SPodUIDKey, EnvPodUID, path.Join(PodInfoPath, "uid"))
+	lookup(semconv.K8SNamespaceNameKey, EnvNamespaceName, path.Join(PodInfoPath, "namespace"), serviceAccountNamespacePath)
+	lookup(semconv.K8SNodeNameKey, EnvNodeName)
+	lookup(semconv.K8SContainerNameKey, EnvContainerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := src.LookupEnv(envServiceHost); ok && !hasKey(attrs, semconv.K8SPodNameKey) {
+		// The pod's hostname defaults to the pod name
+		name, err := src.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, semconv.K8SPodNameKey.String(name))
+	}
+	return attrs, nil
+}
+
+func hasKey(attrs []attribute.KeyValue, key attribute.Key) bool {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return true
+		}
+	}
+	return false
+}
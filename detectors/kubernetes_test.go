@@ -0,0 +1,82 @@
+package detectors_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
+)
+
+func TestKubernetesDetector(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		files    fstest.MapFS
+		env      map[string]string
+		expect   []attribute.KeyValue
+	}{
+		{
+			scenario: "Downward API variables",
+			files: fstest.MapFS{
+				"etc/podinfo/name": {Data: []byte("ignored\n")},
+			},
+			env: map[string]string{
+				detectors.EnvPodName:       "checkout-7d9f",
+				detectors.EnvPodUID:        "1234",
+				detectors.EnvNamespaceName: "payments",
+				detectors.EnvNodeName:      "node-1",
+				detectors.EnvContainerName: "app",
+			},
+			expect: []attribute.KeyValue{
+				semconv.K8SContainerNameKey.String("app"),
+				semconv.K8SNamespaceNameKey.String("payments"),
+				semconv.K8SNodeNameKey.String("node-1"),
+				semconv.K8SPodNameKey.String("checkout-7d9f"),
+				semconv.K8SPodUIDKey.String("1234"),
+			},
+		},
+		{
+			scenario: "Downward API volume",
+			files: fstest.MapFS{
+				"etc/podinfo/name":      {Data: []byte("checkout-7d9f\n")},
+				"etc/podinfo/uid":       {Data: []byte("1234\n")},
+				"etc/podinfo/namespace": {Data: []byte("payments\n")},
+			},
+			expect: []attribute.KeyValue{
+				semconv.K8SNamespaceNameKey.String("payments"),
+				semconv.K8SPodNameKey.String("checkout-7d9f"),
+				semconv.K8SPodUIDKey.String("1234"),
+			},
+		},
+		{
+			scenario: "Service account and hostname",
+			files: fstest.MapFS{
+				"var/run/secrets/kubernetes.io/serviceaccount/namespace": {Data: []byte("payments")},
+			},
+			env: map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"},
+			expect: []attribute.KeyValue{
+				semconv.K8SNamespaceNameKey.String("payments"),
+				semconv.K8SPodNameKey.String("fake-host"),
+			},
+		},
+		{
+			scenario: "Not within Kubernetes",
+			files:    fstest.MapFS{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			res := detect(t, detectors.Kubernetes, fakeSource(tc.files, tc.env))
+			assert.Equal(t, tc.expect, res.Attributes())
+		})
+	}
+}
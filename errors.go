@@ -0,0 +1,44 @@
+package otelstarter
+
+import "fmt"
+
+// Pipeline identifies which part of the launcher failed
+type Pipeline string
+
+const (
+	PipelineConfig      Pipeline = "config"
+	PipelineMetrics     Pipeline = "metrics"
+	PipelineTracing     Pipeline = "tracing"
+	PipelineLogs        Pipeline = "logs"
+	PipelinePropagators Pipeline = "propagators"
+)
+
+// Stage identifies the step of a pipeline that failed
+type Stage string
+
+const (
+	StageConfigure Stage = "configure"
+	StageExporter  Stage = "exporter"
+	StageStart     Stage = "start"
+	StageFlush     Stage = "flush"
+	StageShutdown  Stage = "shutdown"
+)
+
+// PipelineError is returned by New when a pipeline is unable to be started,
+// and by ShutdownContext when a pipeline is unable to be flushed or shutdown,
+// the original error can be inspected using errors.Is and errors.As
+type PipelineError struct {
+	Pipeline Pipeline
+	Stage    Stage
+	Err      error
+}
+
+var _ error = (*PipelineError)(nil)
+
+func (pe *PipelineError) Error() string {
+	return fmt.Sprintf("%s pipeline failed at %s stage: %v", pe.Pipeline, pe.Stage, pe.Err)
+}
+
+func (pe *PipelineError) Unwrap() error {
+	return pe.Err
+}
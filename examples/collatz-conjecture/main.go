@@ -158,7 +158,7 @@ func main() {
 				config.WithExporterNamed("zipkin"),
 				config.WithExporterEndpoint("http://localhost:9411/api/v2/spans"),
 			),
-			config.WithTracingSampled(),
+			config.WithTracingSampler(config.SamplerAlwaysOn, 0),
 		),
 	).Shutdown()
 
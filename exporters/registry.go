@@ -0,0 +1,176 @@
+// Package exporters allows custom exporters to be registered by name
+// so that they can be selected using config.WithExporterNamed.
+package exporters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/log"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/metric"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/trace"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// ErrDuplicateExporter is returned when registering an exporter with a name already in use
+var ErrDuplicateExporter = errors.New("exporter already registered")
+
+// TraceFactoryFunc creates a span exporter from the exporter configuration
+type TraceFactoryFunc func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error)
+
+// MetricFactoryFunc creates a metric exporter from the exporter configuration
+type MetricFactoryFunc func(ctx context.Context, conf *config.Export) (sdkmetric.Exporter, error)
+
+// LogFactoryFunc creates a log record exporter from the exporter configuration
+type LogFactoryFunc func(ctx context.Context, conf *config.Export) (logs.Exporter, error)
+
+// registry holds the factories of each pipeline, a factory is called without
+// holding the lock so that it is able to register exporters and a slow factory
+// does not block registrations
+var registry = struct {
+	sync.RWMutex
+
+	trace  trace.ExporterFactory
+	metric metric.Factory
+	log    log.ExporterFactory
+}{
+	trace:  trace.NewExporterFactory(),
+	metric: metric.NewExporterFactory(),
+	log:    log.NewExporterFactory(),
+}
+
+// RegisterTrace adds the span exporter factory under name,
+// the name must not already be used by a built in or registered exporter.
+func RegisterTrace(name string, fn TraceFactoryFunc) error {
+	if err := validate(name, fn == nil); err != nil {
+		return err
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exist := registry.trace[name]; exist {
+		return fmt.Errorf("trace exporter %s: %w", name, ErrDuplicateExporter)
+	}
+	registry.trace[name] = func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
+		return fn(ctx, conf)
+	}
+	return nil
+}
+
+// RegisterMetric adds the metric exporter factory under name,
+// the name must not already be used by a built in or registered exporter.
+func RegisterMetric(name string, fn MetricFactoryFunc) error {
+	if err := validate(name, fn == nil); err != nil {
+		return err
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exist := registry.metric[name]; exist {
+		return fmt.Errorf("metric exporter %s: %w", name, ErrDuplicateExporter)
+	}
+	registry.metric[name] = func(ctx context.Context, conf *config.Export) (sdkmetric.Exporter, error) {
+		return fn(ctx, conf)
+	}
+	return nil
+}
+
+// RegisterLog adds the log record exporter factory under name,
+// the name must not already be used by a built in or registered exporter.
+func RegisterLog(name string, fn LogFactoryFunc) error {
+	if err := validate(name, fn == nil); err != nil {
+		return err
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exist := registry.log[name]; exist {
+		return fmt.Errorf("log exporter %s: %w", name, ErrDuplicateExporter)
+	}
+	registry.log[name] = func(ctx context.Context, conf *config.Export) (logs.Exporter, error) {
+		return fn(ctx, conf)
+	}
+	return nil
+}
+
+func validate(name string, missing bool) error {
+	if name == "" {
+		return fmt.Errorf("exporter name is empty: %w", config.ErrInvalidParam)
+	}
+	if missing {
+		return fmt.Errorf("exporter %s factory is nil: %w", name, config.ErrNilParamProvided)
+	}
+	return nil
+}
+
+// TraceNames returns the sorted names of all the span exporters that can be used
+func TraceNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return registry.trace.Names()
+}
+
+// MetricNames returns the sorted names of all the metric exporters that can be used
+func MetricNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return registry.metric.Names()
+}
+
+// LogNames returns the sorted names of all the log record exporters that can be used
+func LogNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	return registry.log.Names()
+}
+
+// NewTraceExporter creates the span exporter named by the configuration
+func NewTraceExporter(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
+	registry.RLock()
+	factory, exist := registry.trace[conf.Named]
+	if !exist {
+		defer registry.RUnlock()
+		return registry.trace.NewExporter(ctx, conf)
+	}
+	registry.RUnlock()
+
+	return factory(ctx, conf)
+}
+
+// NewMetricExporter creates the metric exporter named by the configuration
+func NewMetricExporter(ctx context.Context, conf *config.Export) (sdkmetric.Exporter, error) {
+	registry.RLock()
+	factory, exist := registry.metric[conf.Named]
+	if !exist {
+		defer registry.RUnlock()
+		return registry.metric.NewExporter(ctx, conf)
+	}
+	registry.RUnlock()
+
+	return factory(ctx, conf)
+}
+
+// NewLogExporter creates the log record exporter named by the configuration
+func NewLogExporter(ctx context.Context, conf *config.Export) (logs.Exporter, error) {
+	registry.RLock()
+	factory, exist := registry.log[conf.Named]
+	if !exist {
+		defer registry.RUnlock()
+		return registry.log.NewExporter(ctx, conf)
+	}
+	registry.RUnlock()
+
+	return factory(ctx, conf)
+}
@@ -0,0 +1,130 @@
+package exporters_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/exporters"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+var registered int64
+
+// uniqueName returns a new exporter name each time the tests are run
+// since the registry does not allow names to be registered twice
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, atomic.AddInt64(&registered, 1))
+}
+
+func TestRegisteringTraceExporter(t *testing.T) {
+	t.Parallel()
+
+	name := uniqueName("registry-test-trace")
+	inmemory := tracetest.NewInMemoryExporter()
+	require.NoError(t, exporters.RegisterTrace(name, func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+		return inmemory, nil
+	}), "Must not error when registering a new exporter")
+
+	assert.Contains(t, exporters.TraceNames(), name)
+	assert.Contains(t, exporters.TraceNames(), "otlpgrpc", "Must include the built in exporters")
+
+	exporter, err := exporters.NewTraceExporter(context.Background(), &config.Export{Named: name})
+	assert.NoError(t, err, "Must not error creating a registered exporter")
+	assert.Equal(t, inmemory, exporter)
+
+	_, err = exporters.NewTraceExporter(context.Background(), &config.Export{Named: "registry-test-missing"})
+	if assert.Error(t, err, "Must error with an unknown exporter") {
+		assert.Contains(t, err.Error(), name, "Must list the registered exporters")
+	}
+}
+
+func TestRegisteringMetricExporter(t *testing.T) {
+	t.Parallel()
+
+	name := uniqueName("registry-test-metric")
+	require.NoError(t, exporters.RegisterMetric(name, func(_ context.Context, _ *config.Export) (sdkmetric.Exporter, error) {
+		return nil, nil
+	}), "Must not error when registering a new exporter")
+
+	assert.Contains(t, exporters.MetricNames(), name)
+	assert.Contains(t, exporters.MetricNames(), "stdout", "Must include the built in exporters")
+}
+
+func TestRegisteringLogExporter(t *testing.T) {
+	t.Parallel()
+
+	name := uniqueName("registry-test-log")
+	require.NoError(t, exporters.RegisterLog(name, func(_ context.Context, _ *config.Export) (logs.Exporter, error) {
+		return nil, nil
+	}), "Must not error when registering a new exporter")
+
+	assert.Contains(t, exporters.LogNames(), name)
+	assert.Contains(t, exporters.LogNames(), "file", "Must include the built in exporters")
+	assert.ErrorIs(t, exporters.RegisterLog("otlpgrpc", func(_ context.Context, _ *config.Export) (logs.Exporter, error) {
+		return nil, nil
+	}), exporters.ErrDuplicateExporter, "Must not replace built in exporters")
+}
+
+func TestFactoryRegistersExporter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		name, lazy = uniqueName("registry-test-outer"), uniqueName("registry-test-lazy")
+		inmemory   = tracetest.NewInMemoryExporter()
+	)
+	require.NoError(t, exporters.RegisterTrace(name, func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
+		if err := exporters.RegisterLog(lazy, func(_ context.Context, _ *config.Export) (logs.Exporter, error) {
+			return nil, nil
+		}); err != nil {
+			return nil, err
+		}
+		return inmemory, nil
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		exporter, err := exporters.NewTraceExporter(context.Background(), &config.Export{Named: name})
+		assert.NoError(t, err)
+		assert.Equal(t, inmemory, exporter)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "Must not hold the registry lock while calling the factory")
+	}
+	assert.Contains(t, exporters.LogNames(), lazy, "Must register the exporter from within the factory")
+}
+
+func TestInvalidRegistrations(t *testing.T) {
+	t.Parallel()
+
+	noopTrace := func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+		return tracetest.NewNoopExporter(), nil
+	}
+	noopMetric := func(_ context.Context, _ *config.Export) (sdkmetric.Exporter, error) {
+		return nil, nil
+	}
+
+	assert.ErrorIs(t, exporters.RegisterTrace("stdout", noopTrace), exporters.ErrDuplicateExporter, "Must not replace built in exporters")
+	assert.ErrorIs(t, exporters.RegisterMetric("otlphttp", noopMetric), exporters.ErrDuplicateExporter, "Must not replace built in exporters")
+
+	duplicate := uniqueName("registry-test-duplicate")
+	require.NoError(t, exporters.RegisterTrace(duplicate, noopTrace))
+	assert.ErrorIs(t, exporters.RegisterTrace(duplicate, noopTrace), exporters.ErrDuplicateExporter)
+
+	assert.ErrorIs(t, exporters.RegisterTrace("", noopTrace), config.ErrInvalidParam)
+	assert.ErrorIs(t, exporters.RegisterTrace("registry-test-nil", nil), config.ErrNilParamProvided)
+	assert.ErrorIs(t, exporters.RegisterMetric("registry-test-nil", nil), config.ErrNilParamProvided)
+}
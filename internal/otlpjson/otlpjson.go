@@ -0,0 +1,88 @@
+// Package otlpjson encodes OTLP messages using the OTLP/JSON format,
+// which differs from the protobuf JSON mapping by encoding the
+// trace and span IDs as hex strings instead of base64 and enums as integers.
+package otlpjson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// idFields are the bytes fields encoded as hex by OTLP/JSON
+var idFields = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+}
+
+// Marshal encodes the message as a single line of OTLP/JSON,
+// enums are encoded as integers as required by the format
+func Marshal(m proto.Message) ([]byte, error) {
+	b, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return convertIDs(b, func(s string) (string, error) {
+		id, err := base64.StdEncoding.DecodeString(s)
+		return hex.EncodeToString(id), err
+	})
+}
+
+// Unmarshal decodes the OTLP/JSON document into the message,
+// unknown fields are ignored so that newer versions of the format can be read
+func Unmarshal(b []byte, m proto.Message) error {
+	b, err := convertIDs(b, func(s string) (string, error) {
+		id, err := hex.DecodeString(s)
+		return base64.StdEncoding.EncodeToString(id), err
+	})
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, m)
+}
+
+// convertIDs re-encodes the ID fields found anywhere within the document
+func convertIDs(b []byte, convert func(s string) (string, error)) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if err := walk(doc, convert); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+func walk(v interface{}, convert func(s string) (string, error)) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if s, ok := field.(string); ok && idFields[k] {
+				id, err := convert(s)
+				if err != nil {
+					return fmt.Errorf("invalid %s %q: %w", k, s, err)
+				}
+				v[k] = id
+				continue
+			}
+			if err := walk(field, convert); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := walk(item, convert); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package otlpjson_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	record := &logspb.LogRecord{
+		TimeUnixNano:   1637712000000000000,
+		TraceId:        []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanId:         []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_WARN,
+	}
+
+	b, err := otlpjson.Marshal(record)
+	require.NoError(t, err, "Must encode the record")
+	assert.Contains(t, string(b), `"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"`, "Must hex encode the trace ID")
+	assert.Contains(t, string(b), `"spanId":"00f067aa0ba902b7"`, "Must hex encode the span ID")
+	assert.Contains(t, string(b), `"severityNumber":13`, "Must encode enums as integers")
+	assert.NotContains(t, string(b), "\n", "Must encode the record on a single line")
+
+	var decoded logspb.LogRecord
+	require.NoError(t, otlpjson.Unmarshal(b, &decoded), "Must decode the record")
+	assert.True(t, proto.Equal(record, &decoded), "Must decode the same record")
+}
+
+func TestUnmarshalInvalidID(t *testing.T) {
+	t.Parallel()
+
+	var decoded logspb.LogRecord
+	assert.Error(t, otlpjson.Unmarshal([]byte(`{"traceId":"not-hex"}`), &decoded))
+	assert.NoError(t, otlpjson.Unmarshal([]byte(`{"unknownField":true}`), &decoded), "Must ignore unknown fields")
+}
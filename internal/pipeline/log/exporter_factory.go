@@ -0,0 +1,53 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+var ErrNotDefinedExporter = errors.New("invalid exporter provided")
+
+type ExporterFactory map[string]generatorFunc
+
+type generatorFunc func(ctx context.Context, conf *config.Export) (logs.Exporter, error)
+
+func (ef ExporterFactory) NewExporter(ctx context.Context, conf *config.Export) (logs.Exporter, error) {
+	factory, exist := ef[conf.Named]
+	if !exist {
+		return nil, fmt.Errorf("unknown exporter %s, must be one of [%s]: %w", conf.Named, strings.Join(ef.Names(), ", "), ErrNotDefinedExporter)
+	}
+	return factory(ctx, conf)
+}
+
+// Names returns the sorted names of the exporters within the factory
+func (ef ExporterFactory) Names() []string {
+	names := make([]string, 0, len(ef))
+	for name := range ef {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func NewExporterFactory() ExporterFactory {
+	return map[string]generatorFunc{
+		"otlpgrpc": newGRPCExporter,
+		"otlphttp": newHTTPExporter,
+		"stdout": func(_ context.Context, _ *config.Export) (logs.Exporter, error) {
+			return NewWriterExporter(os.Stdout), nil
+		},
+		"file": func(_ context.Context, conf *config.Export) (logs.Exporter, error) {
+			if conf.Path == "" {
+				return nil, fmt.Errorf("file exporter requires a path: %w", config.ErrInvalidParam)
+			}
+			return newFileExporter(conf.Path, conf.File)
+		},
+	}
+}
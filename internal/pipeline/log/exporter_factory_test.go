@@ -0,0 +1,303 @@
+package log_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/log"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/retrytest"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+func testRecords(t *testing.T) []logs.Record {
+	t.Helper()
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	return []logs.Record{{
+		Timestamp:  time.Unix(1637712000, 0),
+		Severity:   logs.SeverityError,
+		Body:       "card declined",
+		Attributes: []attribute.KeyValue{attribute.Int("attempts", 3)},
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Scope:      "payments",
+		Resource:   resource.NewSchemaless(attribute.String("service.name", "checkout")),
+	}}
+}
+
+// assertRequest checks the request contains the records from testRecords
+func assertRequest(t *testing.T, req *collogspb.ExportLogsServiceRequest) {
+	t.Helper()
+
+	require.Len(t, req.ResourceLogs, 1, "Must group the records by resource")
+	rl := req.ResourceLogs[0]
+	assert.Equal(t, "service.name", rl.Resource.Attributes[0].Key)
+	require.Len(t, rl.InstrumentationLibraryLogs, 1, "Must group the records by scope")
+	assert.Equal(t, "payments", rl.InstrumentationLibraryLogs[0].InstrumentationLibrary.Name)
+	require.Len(t, rl.InstrumentationLibraryLogs[0].Logs, 1)
+
+	lr := rl.InstrumentationLibraryLogs[0].Logs[0]
+	assert.Equal(t, "card declined", lr.Body.GetStringValue())
+	assert.Equal(t, "ERROR", lr.SeverityText)
+	assert.EqualValues(t, logs.SeverityError, lr.SeverityNumber)
+	assert.Equal(t, uint64(time.Unix(1637712000, 0).UnixNano()), lr.TimeUnixNano)
+	assert.Equal(t, []byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}, lr.TraceId)
+	assert.Equal(t, []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}, lr.SpanId)
+	assert.EqualValues(t, trace.FlagsSampled, lr.Flags)
+	assert.Equal(t, int64(3), lr.Attributes[0].Value.GetIntValue())
+}
+
+type logsServer struct {
+	collogspb.UnimplementedLogsServiceServer
+
+	mu       sync.Mutex
+	requests []*collogspb.ExportLogsServiceRequest
+	headers  []metadata.MD
+}
+
+func (ls *logsServer) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	ls.requests = append(ls.requests, req)
+	ls.headers = append(ls.headers, md)
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+func TestGRPCExporter(t *testing.T) {
+	t.Parallel()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	recv := &logsServer{}
+	srv := grpc.NewServer()
+	collogspb.RegisterLogsServiceServer(srv, recv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	exporter, err := log.NewExporterFactory().NewExporter(ctx, &config.Export{
+		Named:          "otlpgrpc",
+		Endpoint:       "http://" + lis.Addr().String(),
+		Headers:        map[string]string{"api-key": "secret"},
+		UseCompression: true,
+	})
+	require.NoError(t, err, "Must create the exporter")
+
+	require.NoError(t, exporter.Export(ctx, testRecords(t)), "Must export the records")
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+
+	require.Len(t, recv.requests, 1, "Must have sent a single request")
+	assertRequest(t, recv.requests[0])
+	assert.Equal(t, []string{"secret"}, recv.headers[0].Get("api-key"), "Must send the configured headers")
+}
+
+func TestHTTPExporter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests []*http.Request
+		bodies   []*collogspb.ExportLogsServiceRequest
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gr, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err, "Must send a valid gzip body") {
+				rw.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = gr
+		}
+		raw, err := io.ReadAll(body)
+		assert.NoError(t, err)
+
+		var req collogspb.ExportLogsServiceRequest
+		assert.NoError(t, proto.Unmarshal(raw, &req), "Must send a protobuf body")
+
+		mu.Lock()
+		requests, bodies = append(requests, r), append(bodies, &req)
+		mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	exporter, err := log.NewExporterFactory().NewExporter(ctx, &config.Export{
+		Named:          "otlphttp",
+		Endpoint:       s.URL,
+		Headers:        map[string]string{"Api-Key": "secret"},
+		UseCompression: true,
+	})
+	require.NoError(t, err, "Must create the exporter")
+
+	require.NoError(t, exporter.Export(ctx, testRecords(t)), "Must export the records")
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, requests, 1, "Must have sent a single request")
+	assert.Equal(t, "/v1/logs", requests[0].URL.Path)
+	assert.Equal(t, "secret", requests[0].Header.Get("Api-Key"))
+	assert.Equal(t, "application/x-protobuf", requests[0].Header.Get("Content-Type"))
+	assertRequest(t, bodies[0])
+}
+
+func TestHTTPExporterRejected(t *testing.T) {
+	t.Parallel()
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(s.Close)
+
+	exporter, err := log.NewExporterFactory().NewExporter(context.Background(), &config.Export{
+		Named:    "otlphttp",
+		Endpoint: s.URL,
+	})
+	require.NoError(t, err)
+	assert.Error(t, exporter.Export(context.Background(), testRecords(t)), "Must not retry rejected requests")
+}
+
+func TestHTTPExporterURLPath(t *testing.T) {
+	t.Parallel()
+
+	paths := make(chan string, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+	}))
+	t.Cleanup(s.Close)
+
+	exporter, err := log.NewExporterFactory().NewExporter(context.Background(), &config.Export{
+		Named:    "otlphttp",
+		Endpoint: s.URL + "/gateway/logs",
+	})
+	require.NoError(t, err)
+	require.NoError(t, exporter.Export(context.Background(), testRecords(t)))
+	assert.Equal(t, "/gateway/logs", <-paths, "Must send to the endpoint's path")
+}
+
+func TestHTTPExporterPartialSuccess(t *testing.T) {
+	t.Parallel()
+
+	// ExportLogsServiceResponse{partial_success: {rejected_log_records: 1, error_message: "too old"}}
+	var partial []byte
+	partial = protowire.AppendTag(partial, 1, protowire.VarintType)
+	partial = protowire.AppendVarint(partial, 1)
+	partial = protowire.AppendTag(partial, 2, protowire.BytesType)
+	partial = protowire.AppendString(partial, "too old")
+	var resp []byte
+	resp = protowire.AppendTag(resp, 1, protowire.BytesType)
+	resp = protowire.AppendBytes(resp, partial)
+
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = rw.Write(resp)
+	}))
+	t.Cleanup(s.Close)
+
+	exporter, err := log.NewExporterFactory().NewExporter(context.Background(), &config.Export{
+		Named:    "otlphttp",
+		Endpoint: s.URL,
+	})
+	require.NoError(t, err)
+
+	err = exporter.Export(context.Background(), testRecords(t))
+	assert.ErrorIs(t, err, log.ErrPartialSuccess, "Must report the rejected records")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `1 log records rejected: "too old"`)
+	}
+}
+
+func TestOTLPExporterRetries(t *testing.T) {
+	t.Parallel()
+
+	retrytest.Run(t, func(t *testing.T, ctx context.Context, conf *config.Export) error {
+		exporter, err := log.NewExporterFactory().NewExporter(ctx, conf)
+		require.NoError(t, err, "Must not error when configuring exporter")
+
+		err = exporter.Export(ctx, testRecords(t))
+		assert.NoError(t, exporter.Shutdown(ctx))
+		return err
+	})
+}
+
+func TestFileExporter(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+	ctx := context.Background()
+
+	exporter, err := log.NewExporterFactory().NewExporter(ctx, &config.Export{Named: "file", Path: path})
+	require.NoError(t, err, "Must create the exporter")
+
+	require.NoError(t, exporter.Export(ctx, testRecords(t)))
+	require.NoError(t, exporter.Export(ctx, testRecords(t)))
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines int
+	for scanner := bufio.NewScanner(f); scanner.Scan(); lines++ {
+		assert.Contains(t, scanner.Text(), `"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+
+		var req collogspb.ExportLogsServiceRequest
+		require.NoError(t, otlpjson.Unmarshal(scanner.Bytes(), &req), "Must write OTLP/JSON")
+		assertRequest(t, &req)
+	}
+	assert.Equal(t, 2, lines, "Must write each export on its own line")
+}
+
+func TestInvalidExporters(t *testing.T) {
+	t.Parallel()
+
+	factory := log.NewExporterFactory()
+	assert.Equal(t, []string{"file", "otlpgrpc", "otlphttp", "stdout"}, factory.Names())
+
+	_, err := factory.NewExporter(context.Background(), &config.Export{Named: "zipkin"})
+	assert.ErrorIs(t, err, log.ErrNotDefinedExporter)
+
+	_, err = factory.NewExporter(context.Background(), &config.Export{Named: "file"})
+	assert.ErrorIs(t, err, config.ErrInvalidParam, "Must require a path for the file exporter")
+}
@@ -0,0 +1,333 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpcgzip "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// Defaults matching the OTLP trace and metric exporters
+const (
+	defaultGRPCEndpoint = "localhost:4317"
+	defaultHTTPEndpoint = "localhost:4318"
+	defaultTimeout      = 10 * time.Second
+)
+
+// ErrPartialSuccess is returned when the collector accepts the export
+// but reports that some of the records were rejected
+var ErrPartialSuccess = errors.New("export partially succeeded")
+
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	client  collogspb.LogsServiceClient
+	headers metadata.MD
+	timeout time.Duration
+	retry   config.Retry
+}
+
+var _ logs.Exporter = (*grpcExporter)(nil)
+
+func newGRPCExporter(ctx context.Context, conf *config.Export) (logs.Exporter, error) {
+	tlsConf, err := conf.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := defaultGRPCEndpoint
+	if conf.Endpoint != "" {
+		endpoint = conf.HostPort()
+	}
+
+	var dialOpts []grpc.DialOption
+	if conf.Insecure() {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	} else {
+		if tlsConf == nil {
+			tlsConf = &tls.Config{}
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConf)))
+	}
+	if conf.UseCompression {
+		dialOpts = append(dialOpts, grpc.WithDefaultCallOptions(grpc.UseCompressor(grpcgzip.Name)))
+	}
+
+	// The connection is established in the background
+	// so that the collector is not required to be running at start up
+	conn, err := grpc.DialContext(ctx, endpoint, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &grpcExporter{
+		conn:    conn,
+		client:  collogspb.NewLogsServiceClient(conn),
+		headers: metadata.New(conf.Headers),
+		timeout: exportTimeout(conf),
+		retry:   exportRetry(conf),
+	}, nil
+}
+
+func (ge *grpcExporter) Export(ctx context.Context, records []logs.Record) error {
+	req := NewExportRequest(records)
+	return retry(ctx, ge.retry, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, ge.timeout)
+		defer cancel()
+
+		resp, err := ge.client.Export(metadata.NewOutgoingContext(ctx, ge.headers), req)
+		switch status.Code(err) {
+		case codes.OK:
+			return partialSuccess(resp.ProtoReflect().GetUnknown())
+		case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted,
+			codes.Aborted, codes.OutOfRange, codes.Unavailable, codes.DataLoss:
+			return &retryableError{err: err}
+		}
+		return err
+	})
+}
+
+func (ge *grpcExporter) Shutdown(ctx context.Context) error {
+	return ge.conn.Close()
+}
+
+type httpExporter struct {
+	client      *http.Client
+	url         string
+	headers     map[string]string
+	compression bool
+	timeout     time.Duration
+	retry       config.Retry
+}
+
+var _ logs.Exporter = (*httpExporter)(nil)
+
+func newHTTPExporter(_ context.Context, conf *config.Export) (logs.Exporter, error) {
+	tlsConf, err := conf.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	u := url.URL{Scheme: "https", Host: defaultHTTPEndpoint, Path: "/v1/logs"}
+	if conf.Endpoint != "" {
+		u.Host = conf.HostPort()
+	}
+	if path := conf.URLPath("logs"); path != "" {
+		u.Path = path
+	}
+	if conf.Insecure() {
+		u.Scheme = "http"
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConf
+	return &httpExporter{
+		client:      &http.Client{Transport: transport},
+		url:         u.String(),
+		headers:     conf.Headers,
+		compression: conf.UseCompression,
+		timeout:     exportTimeout(conf),
+		retry:       exportRetry(conf),
+	}, nil
+}
+
+func (he *httpExporter) Export(ctx context.Context, records []logs.Record) error {
+	body, err := proto.Marshal(NewExportRequest(records))
+	if err != nil {
+		return err
+	}
+	if he.compression {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		if _, err := gw.Write(body); err != nil {
+			return err
+		}
+		if err := gw.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	return retry(ctx, he.retry, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, he.timeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, he.url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		for k, v := range he.headers {
+			req.Header.Set(k, v)
+		}
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		if he.compression {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+
+		resp, err := he.client.Do(req)
+		if err != nil {
+			return &retryableError{err: err}
+		}
+		// The body is read in full so that the connection is reused
+		respBody, rerr := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK, http.StatusAccepted:
+			if rerr != nil {
+				return rerr
+			}
+			return partialSuccess(respBody)
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return &retryableError{err: fmt.Errorf("%s returned %s", he.url, resp.Status)}
+		}
+		return fmt.Errorf("%s returned %s", he.url, resp.Status)
+	})
+}
+
+func (he *httpExporter) Shutdown(ctx context.Context) error {
+	he.client.CloseIdleConnections()
+	return nil
+}
+
+func exportTimeout(conf *config.Export) time.Duration {
+	if conf.Timeout > 0 {
+		return conf.Timeout
+	}
+	return defaultTimeout
+}
+
+func exportRetry(conf *config.Export) config.Retry {
+	if conf.Retry != nil {
+		return *conf.Retry
+	}
+	return config.NewDefaultRetry()
+}
+
+// retryableError marks a failed export that is able to be sent again
+type retryableError struct {
+	err error
+}
+
+func (re *retryableError) Error() string {
+	return re.err.Error()
+}
+
+func (re *retryableError) Unwrap() error {
+	return re.err
+}
+
+// retry calls export until it succeeds, returns an error that is not retryable,
+// or the max elapsed time has passed. The intervals between the attempts are
+// randomised in the same way as the OTLP trace and metric exporters.
+func retry(ctx context.Context, r config.Retry, export func(ctx context.Context) error) error {
+	if !r.Enabled {
+		return unwrapRetryable(export(ctx))
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = r.InitialInterval
+	b.MaxInterval = r.MaxInterval
+	b.MaxElapsedTime = r.MaxElapsedTime
+	b.Reset()
+
+	err := backoff.Retry(func() error {
+		err := export(ctx)
+		var re *retryableError
+		if err != nil && !errors.As(err, &re) {
+			return backoff.Permanent(err)
+		}
+		return err
+	}, backoff.WithContext(b, ctx))
+	return unwrapRetryable(err)
+}
+
+func unwrapRetryable(err error) error {
+	var re *retryableError
+	if errors.As(err, &re) {
+		return re.err
+	}
+	return err
+}
+
+// partialSuccess reads the partial_success field of an export response,
+// the field was added to the OTLP protocol after the version used by this module
+// so it is decoded from the response's raw bytes
+func partialSuccess(b []byte) error {
+	var (
+		rejected int64
+		message  string
+	)
+	err := consumeFields(b, func(num protowire.Number, v []byte) error {
+		if num != 1 {
+			return nil
+		}
+		// ExportLogsPartialSuccess
+		partial, n := protowire.ConsumeBytes(v)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		return consumeFields(partial, func(num protowire.Number, v []byte) error {
+			switch num {
+			case 1: // rejected_log_records
+				count, n := protowire.ConsumeVarint(v)
+				if n < 0 {
+					return protowire.ParseError(n)
+				}
+				rejected = int64(count)
+			case 2: // error_message
+				msg, n := protowire.ConsumeString(v)
+				if n < 0 {
+					return protowire.ParseError(n)
+				}
+				message = msg
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("invalid export response: %w", err)
+	}
+	if rejected == 0 && message == "" {
+		return nil
+	}
+	return fmt.Errorf("%d log records rejected: %q: %w", rejected, message, ErrPartialSuccess)
+}
+
+// consumeFields calls fn with the number and the encoded value of each field in b
+func consumeFields(b []byte, fn func(num protowire.Number, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err := fn(num, b[:n]); err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
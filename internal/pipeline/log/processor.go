@@ -0,0 +1,183 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// Defaults defined by the specification for the batch log record processor
+const (
+	defaultMaxQueueSize       = 2048
+	defaultMaxExportBatchSize = 512
+	defaultScheduleDelay      = time.Second
+	defaultExportTimeout      = 30 * time.Second
+)
+
+// BatchProcessor buffers records and sends them to the exporter in batches,
+// once the batch is full or the batch timeout has passed
+type BatchProcessor struct {
+	exporter logs.Exporter
+	batch    config.Batch
+	handler  otel.ErrorHandler
+
+	queue chan logs.Record
+	flush chan chan error
+	stop  chan struct{}
+	done  chan struct{}
+
+	stopOnce sync.Once
+}
+
+// NewBatchProcessor starts the processor for the exporter,
+// zero values of the batch settings use the specification defaults.
+// Errors from background exports are sent to the handler,
+// the global error handler is used when it is nil.
+func NewBatchProcessor(exporter logs.Exporter, b config.Batch, handler otel.ErrorHandler) *BatchProcessor {
+	if b.MaxQueueSize <= 0 {
+		b.MaxQueueSize = defaultMaxQueueSize
+	}
+	if b.MaxExportBatchSize <= 0 {
+		b.MaxExportBatchSize = defaultMaxExportBatchSize
+	}
+	if b.MaxExportBatchSize > b.MaxQueueSize {
+		b.MaxExportBatchSize = b.MaxQueueSize
+	}
+	if b.Timeout <= 0 {
+		b.Timeout = defaultScheduleDelay
+	}
+	if b.ExportTimeout <= 0 {
+		b.ExportTimeout = defaultExportTimeout
+	}
+	if handler == nil {
+		handler = otel.GetErrorHandler()
+	}
+
+	bp := &BatchProcessor{
+		exporter: exporter,
+		batch:    b,
+		handler:  handler,
+		queue:    make(chan logs.Record, b.MaxQueueSize),
+		flush:    make(chan chan error),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go bp.run()
+	return bp
+}
+
+// OnEmit queues the record, the record is dropped when the queue is full
+// unless the processor is configured to block on a full queue
+func (bp *BatchProcessor) OnEmit(r logs.Record) {
+	if bp.batch.BlockOnQueueFull {
+		select {
+		case bp.queue <- r:
+		case <-bp.stop:
+		}
+		return
+	}
+	select {
+	case bp.queue <- r:
+	default:
+	}
+}
+
+// ForceFlush exports the queued records
+func (bp *BatchProcessor) ForceFlush(ctx context.Context) error {
+	result := make(chan error, 1)
+	select {
+	case bp.flush <- result:
+	case <-bp.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown exports the queued records and shuts down the exporter,
+// records emitted afterwards are dropped
+func (bp *BatchProcessor) Shutdown(ctx context.Context) error {
+	bp.stopOnce.Do(func() {
+		close(bp.stop)
+	})
+	select {
+	case <-bp.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return bp.exporter.Shutdown(ctx)
+}
+
+func (bp *BatchProcessor) run() {
+	defer close(bp.done)
+
+	ticker := time.NewTicker(bp.batch.Timeout)
+	defer ticker.Stop()
+
+	var batch []logs.Record
+	export := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), bp.batch.ExportTimeout)
+		defer cancel()
+
+		// The exporter may hold onto the batch so a new one is started
+		err := bp.exporter.Export(ctx, batch)
+		batch = nil
+		return err
+	}
+	// drain moves the queued records into batches,
+	// exporting any batches that become full
+	drain := func() (err error) {
+		for {
+			select {
+			case r := <-bp.queue:
+				if batch = append(batch, r); len(batch) >= bp.batch.MaxExportBatchSize {
+					if eerr := export(); eerr != nil {
+						err = eerr
+					}
+				}
+			default:
+				if eerr := export(); eerr != nil {
+					err = eerr
+				}
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case r := <-bp.queue:
+			if batch = append(batch, r); len(batch) >= bp.batch.MaxExportBatchSize {
+				bp.handle(export())
+			}
+		case <-ticker.C:
+			bp.handle(export())
+		case result := <-bp.flush:
+			result <- drain()
+		case <-bp.stop:
+			bp.handle(drain())
+			return
+		}
+	}
+}
+
+// handle reports errors from background exports
+func (bp *BatchProcessor) handle(err error) {
+	if err != nil {
+		bp.handler.Handle(err)
+	}
+}
@@ -0,0 +1,158 @@
+package log_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/log"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// batchExporter records the size of each exported batch
+type batchExporter struct {
+	mu       sync.Mutex
+	batches  [][]logs.Record
+	shutdown bool
+}
+
+func (be *batchExporter) Export(_ context.Context, records []logs.Record) error {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	be.batches = append(be.batches, records)
+	return nil
+}
+
+func (be *batchExporter) Shutdown(context.Context) error {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	be.shutdown = true
+	return nil
+}
+
+func (be *batchExporter) sizes() (sizes []int) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	for _, b := range be.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func TestBatchProcessorBatchSize(t *testing.T) {
+	t.Parallel()
+
+	exporter := &batchExporter{}
+	bp := log.NewBatchProcessor(exporter, config.Batch{
+		MaxExportBatchSize: 2,
+		Timeout:            time.Hour,
+		BlockOnQueueFull:   true,
+	}, nil)
+	for i := 0; i < 5; i++ {
+		bp.OnEmit(logs.Record{Body: "record"})
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(exporter.sizes()) == 2
+	}, 5*time.Second, 10*time.Millisecond, "Must export each full batch")
+
+	require.NoError(t, bp.ForceFlush(context.Background()))
+	assert.Equal(t, []int{2, 2, 1}, exporter.sizes(), "Must export the remaining records on flush")
+
+	require.NoError(t, bp.Shutdown(context.Background()))
+	assert.True(t, exporter.shutdown, "Must shutdown the exporter")
+	assert.NoError(t, bp.ForceFlush(context.Background()), "Must allow flushing after shutdown")
+	bp.OnEmit(logs.Record{Body: "dropped"})
+}
+
+func TestBatchProcessorTimeout(t *testing.T) {
+	t.Parallel()
+
+	exporter := &batchExporter{}
+	bp := log.NewBatchProcessor(exporter, config.Batch{Timeout: 10 * time.Millisecond}, nil)
+	t.Cleanup(func() { _ = bp.Shutdown(context.Background()) })
+
+	bp.OnEmit(logs.Record{Body: "record"})
+	assert.Eventually(t, func() bool {
+		return len(exporter.sizes()) == 1
+	}, 5*time.Second, 10*time.Millisecond, "Must export once the batch timeout has passed")
+}
+
+func TestBatchProcessorShutdownExportsQueued(t *testing.T) {
+	t.Parallel()
+
+	exporter := &batchExporter{}
+	bp := log.NewBatchProcessor(exporter, config.Batch{Timeout: time.Hour}, nil)
+	for i := 0; i < 3; i++ {
+		bp.OnEmit(logs.Record{Body: "record"})
+	}
+
+	require.NoError(t, bp.Shutdown(context.Background()))
+	assert.Equal(t, []int{3}, exporter.sizes(), "Must export the queued records before shutting down")
+}
+
+// failingExporter rejects every export
+type failingExporter struct{}
+
+func (failingExporter) Export(context.Context, []logs.Record) error {
+	return errors.New("collector unavailable")
+}
+
+func (failingExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func TestBatchProcessorReportsToHandler(t *testing.T) {
+	t.Parallel()
+
+	handled := make(chan error, 1)
+	bp := log.NewBatchProcessor(failingExporter{}, config.Batch{Timeout: 10 * time.Millisecond}, otel.ErrorHandlerFunc(func(err error) {
+		select {
+		case handled <- err:
+		default:
+		}
+	}))
+	t.Cleanup(func() { _ = bp.Shutdown(context.Background()) })
+
+	bp.OnEmit(logs.Record{Body: "record"})
+	select {
+	case err := <-handled:
+		assert.EqualError(t, err, "collector unavailable")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Must send the background export error to the handler")
+	}
+}
+
+func TestProviderStampsRecords(t *testing.T) {
+	t.Parallel()
+
+	var (
+		exporter = &batchExporter{}
+		res      = resource.NewSchemaless(attribute.String("service.name", "checkout"))
+		provider = log.NewProvider(res, log.NewBatchProcessor(exporter, config.Batch{}, nil))
+	)
+	provider.Logger("payments").Emit(context.Background(), logs.SeverityInfo, "charged")
+	require.NoError(t, provider.ForceFlush(context.Background()))
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+
+	require.Len(t, exporter.batches, 1)
+	r := exporter.batches[0][0]
+	assert.Equal(t, "payments", r.Scope, "Must use the logger name as the scope")
+	assert.Equal(t, res, r.Resource, "Must use the provider's resource")
+	assert.Equal(t, "charged", r.Body)
+}
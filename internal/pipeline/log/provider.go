@@ -0,0 +1,58 @@
+package log
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// Provider creates loggers that send their records
+// to each of the batch processors
+type Provider struct {
+	resource   *resource.Resource
+	processors []*BatchProcessor
+}
+
+var _ logs.LoggerProvider = (*Provider)(nil)
+
+// NewProvider returns a provider that adds res to each record
+func NewProvider(res *resource.Resource, processors ...*BatchProcessor) *Provider {
+	return &Provider{resource: res, processors: processors}
+}
+
+func (p *Provider) Logger(name string) logs.Logger {
+	return &logger{name: name, provider: p}
+}
+
+// ForceFlush exports the records queued by each processor
+func (p *Provider) ForceFlush(ctx context.Context) (err error) {
+	for _, bp := range p.processors {
+		err = multierr.Append(err, bp.ForceFlush(ctx))
+	}
+	return err
+}
+
+// Shutdown exports the queued records and shuts down each processor's exporter
+func (p *Provider) Shutdown(ctx context.Context) (err error) {
+	for _, bp := range p.processors {
+		err = multierr.Append(err, bp.Shutdown(ctx))
+	}
+	return err
+}
+
+type logger struct {
+	name     string
+	provider *Provider
+}
+
+func (l *logger) Emit(ctx context.Context, severity logs.Severity, body string, attrs ...attribute.KeyValue) {
+	r := logs.NewRecord(ctx, severity, body, attrs...)
+	r.Scope, r.Resource = l.name, l.provider.resource
+	for _, bp := range l.provider.processors {
+		bp.OnEmit(r)
+	}
+}
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// NewExportRequest converts the records into the OTLP export request,
+// grouping the records by their resource and then by their scope
+func NewExportRequest(records []logs.Record) *collogspb.ExportLogsServiceRequest {
+	var (
+		req       = &collogspb.ExportLogsServiceRequest{}
+		resources = make(map[attribute.Distinct]*logspb.ResourceLogs)
+		scopes    = make(map[*logspb.ResourceLogs]map[string]*logspb.InstrumentationLibraryLogs)
+	)
+	for _, r := range records {
+		res := r.Resource
+		if res == nil {
+			res = resource.Empty()
+		}
+		rl, exist := resources[res.Equivalent()]
+		if !exist {
+			rl = &logspb.ResourceLogs{
+				Resource:  &resourcepb.Resource{Attributes: keyValues(res.Attributes())},
+				SchemaUrl: res.SchemaURL(),
+			}
+			resources[res.Equivalent()] = rl
+			scopes[rl] = make(map[string]*logspb.InstrumentationLibraryLogs)
+			req.ResourceLogs = append(req.ResourceLogs, rl)
+		}
+		ill, exist := scopes[rl][r.Scope]
+		if !exist {
+			ill = &logspb.InstrumentationLibraryLogs{
+				InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: r.Scope},
+			}
+			scopes[rl][r.Scope] = ill
+			rl.InstrumentationLibraryLogs = append(rl.InstrumentationLibraryLogs, ill)
+		}
+		ill.Logs = append(ill.Logs, logRecord(r))
+	}
+	return req
+}
+
+func logRecord(r logs.Record) *logspb.LogRecord {
+	lr := &logspb.LogRecord{
+		TimeUnixNano:   uint64(r.Timestamp.UnixNano()),
+		SeverityNumber: logspb.SeverityNumber(r.Severity),
+		SeverityText:   r.Severity.String(),
+		Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: r.Body}},
+		Attributes:     keyValues(r.Attributes),
+		Flags:          uint32(r.TraceFlags),
+	}
+	if r.TraceID.IsValid() {
+		lr.TraceId = append([]byte(nil), r.TraceID[:]...)
+	}
+	if r.SpanID.IsValid() {
+		lr.SpanId = append([]byte(nil), r.SpanID[:]...)
+	}
+	return lr
+}
+
+func keyValues(attrs []attribute.KeyValue) []*commonpb.KeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		kvs = append(kvs, &commonpb.KeyValue{Key: string(kv.Key), Value: anyValue(kv.Value)})
+	}
+	return kvs
+}
+
+func anyValue(v attribute.Value) *commonpb.AnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case attribute.INT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case attribute.FLOAT64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	case attribute.BOOLSLICE:
+		var values []*commonpb.AnyValue
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, anyValue(attribute.BoolValue(b)))
+		}
+		return arrayValue(values)
+	case attribute.INT64SLICE:
+		var values []*commonpb.AnyValue
+		for _, i := range v.AsInt64Slice() {
+			values = append(values, anyValue(attribute.Int64Value(i)))
+		}
+		return arrayValue(values)
+	case attribute.FLOAT64SLICE:
+		var values []*commonpb.AnyValue
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, anyValue(attribute.Float64Value(f)))
+		}
+		return arrayValue(values)
+	case attribute.STRINGSLICE:
+		var values []*commonpb.AnyValue
+		for _, s := range v.AsStringSlice() {
+			values = append(values, anyValue(attribute.StringValue(s)))
+		}
+		return arrayValue(values)
+	}
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v.Emit()}}
+}
+
+func arrayValue(values []*commonpb.AnyValue) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/rotate"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// writerExporter writes each export as a line of OTLP/JSON
+type writerExporter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	close func() error
+}
+
+var _ logs.Exporter = (*writerExporter)(nil)
+
+// NewWriterExporter returns an exporter that writes each batch of records
+// to w as a single line of OTLP/JSON
+func NewWriterExporter(w io.Writer) logs.Exporter {
+	return &writerExporter{w: w, close: func() error { return nil }}
+}
+
+func newFileExporter(path string, conf config.File) (logs.Exporter, error) {
+	f, err := rotate.Open(path, conf)
+	if err != nil {
+		return nil, err
+	}
+	return &writerExporter{w: f, close: f.Close}, nil
+}
+
+func (we *writerExporter) Export(_ context.Context, records []logs.Record) error {
+	b, err := otlpjson.Marshal(NewExportRequest(records))
+	if err != nil {
+		return err
+	}
+
+	we.mu.Lock()
+	defer we.mu.Unlock()
+
+	_, err = we.w.Write(append(b, '\n'))
+	return err
+}
+
+func (we *writerExporter) Shutdown(_ context.Context) error {
+	we.mu.Lock()
+	defer we.mu.Unlock()
+
+	return we.close()
+}
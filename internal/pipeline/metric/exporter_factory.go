@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding/gzip"
 
 	"github.com/MovieStoreGuy/otel-go-starter/config"
@@ -23,51 +27,132 @@ type generatorFunc func(ctx context.Context, pipe *config.Export) (sdkmetric.Exp
 func (ef Factory) NewExporter(ctx context.Context, pipe *config.Export) (sdkmetric.Exporter, error) {
 	factory, exist := ef[pipe.Named]
 	if !exist {
-		return nil, fmt.Errorf("unknown exporter %s: %w", pipe.Named, ErrNotDefinedExporter)
+		return nil, fmt.Errorf("unknown exporter %s, must be one of [%s]: %w", pipe.Named, strings.Join(ef.Names(), ", "), ErrNotDefinedExporter)
 	}
 	return factory(ctx, pipe)
 }
 
+// Names returns the sorted names of the exporters within the factory
+func (ef Factory) Names() []string {
+	names := make([]string, 0, len(ef))
+	for name := range ef {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func NewExporterFactory() Factory {
 	return map[string]generatorFunc{
 		"stdout": func(ctx context.Context, pipe *config.Export) (sdkmetric.Exporter, error) {
 			return stdoutmetric.New(stdoutmetric.WithPrettyPrint())
 		},
-		"otlpgrpc": func(ctx context.Context, pipe *config.Export) (sdkmetric.Exporter, error) {
-			var grpcOpts []otlpmetricgrpc.Option
-
-			if endpoint := pipe.Endpoint; endpoint != "" {
-				grpcOpts = append(grpcOpts, otlpmetricgrpc.WithEndpoint(endpoint))
-			}
-			if headers := pipe.Headers; headers != nil {
-				grpcOpts = append(grpcOpts, otlpmetricgrpc.WithHeaders(headers))
-			}
-			if pipe.AllowInsecure {
-				grpcOpts = append(grpcOpts, otlpmetricgrpc.WithInsecure())
-			}
-			if pipe.UseCompression {
-				grpcOpts = append(grpcOpts, otlpmetricgrpc.WithCompressor(gzip.Name))
-			}
-
-			return otlpmetricgrpc.New(ctx, grpcOpts...)
-		},
-		"otlphttp": func(ctx context.Context, pipe *config.Export) (sdkmetric.Exporter, error) {
-			var httpOpts []otlpmetrichttp.Option
-
-			if endpoint := pipe.Endpoint; endpoint != "" {
-				httpOpts = append(httpOpts, otlpmetrichttp.WithEndpoint(endpoint))
-			}
-			if headers := pipe.Headers; len(headers) != 0 {
-				httpOpts = append(httpOpts, otlpmetrichttp.WithHeaders(headers))
-			}
-			if pipe.AllowInsecure {
-				httpOpts = append(httpOpts, otlpmetrichttp.WithInsecure())
-			}
-			if pipe.UseCompression {
-				httpOpts = append(httpOpts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
-			}
-
-			return otlpmetrichttp.New(ctx, httpOpts...)
+		"otlpgrpc": newOTLPExporter,
+		"otlphttp": newOTLPExporter,
+		"prometheus": func(ctx context.Context, pipe *config.Export) (sdkmetric.Exporter, error) {
+			return NewPrometheusExporter(pipe)
 		},
+		"file": newOTLPExporter,
+	}
+}
+
+// NewClient creates the OTLP client used by the otlpgrpc, otlphttp and file exporters,
+// allowing OTLP payloads to be sent without aggregating them again
+func NewClient(pipe *config.Export) (otlpmetric.Client, error) {
+	switch pipe.Named {
+	case "otlpgrpc":
+		return newOTLPGRPCClient(pipe)
+	case "otlphttp":
+		return newOTLPHTTPClient(pipe)
+	case "file":
+		return newFileClient(pipe)
+	}
+	return nil, fmt.Errorf("exporter %s does not send OTLP payloads: %w", pipe.Named, ErrNotDefinedExporter)
+}
+
+func newOTLPExporter(ctx context.Context, pipe *config.Export) (sdkmetric.Exporter, error) {
+	client, err := NewClient(pipe)
+	if err != nil {
+		return nil, err
+	}
+	return otlpmetric.New(ctx, client)
+}
+
+func newOTLPGRPCClient(pipe *config.Export) (otlpmetric.Client, error) {
+	var grpcOpts []otlpmetricgrpc.Option
+
+	tlsConf, err := pipe.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if pipe.Endpoint != "" {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithEndpoint(pipe.HostPort()))
+	}
+	if headers := pipe.Headers; headers != nil {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithHeaders(headers))
+	}
+	if pipe.Insecure() {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithInsecure())
+	} else if tlsConf != nil {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tlsConf)))
 	}
+	if pipe.UseCompression {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithCompressor(gzip.Name))
+	}
+	if pipe.Timeout > 0 {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithTimeout(pipe.Timeout))
+	}
+	if r := pipe.Retry; r != nil {
+		grpcOpts = append(grpcOpts, otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetrySettings{
+			Enabled:         r.Enabled,
+			InitialInterval: r.InitialInterval,
+			MaxInterval:     r.MaxInterval,
+			MaxElapsedTime:  r.MaxElapsedTime,
+		}))
+	}
+
+	return otlpmetricgrpc.NewClient(grpcOpts...), nil
+}
+
+func newOTLPHTTPClient(pipe *config.Export) (otlpmetric.Client, error) {
+	var httpOpts []otlpmetrichttp.Option
+
+	tlsConf, err := pipe.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if pipe.Endpoint != "" {
+		httpOpts = append(httpOpts, otlpmetrichttp.WithEndpoint(pipe.HostPort()))
+	}
+	if path := pipe.URLPath("metrics"); path != "" {
+		httpOpts = append(httpOpts, otlpmetrichttp.WithURLPath(path))
+	}
+	if headers := pipe.Headers; len(headers) != 0 {
+		httpOpts = append(httpOpts, otlpmetrichttp.WithHeaders(headers))
+	}
+	if pipe.Insecure() {
+		httpOpts = append(httpOpts, otlpmetrichttp.WithInsecure())
+	} else if tlsConf != nil {
+		httpOpts = append(httpOpts, otlpmetrichttp.WithTLSClientConfig(tlsConf))
+	}
+	if pipe.UseCompression {
+		httpOpts = append(httpOpts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
+	}
+	if pipe.Timeout > 0 {
+		httpOpts = append(httpOpts, otlpmetrichttp.WithTimeout(pipe.Timeout))
+	}
+	if r := pipe.Retry; r != nil {
+		// The http exporter only supports a fixed number of attempts using the
+		// initial interval as the base of its backoff, MaxInterval and MaxElapsedTime
+		// are documented as unused by this exporter on config.WithExporterRetry
+		if r.Enabled {
+			httpOpts = append(httpOpts, otlpmetrichttp.WithBackoff(r.InitialInterval))
+		} else {
+			httpOpts = append(httpOpts, otlpmetrichttp.WithMaxAttempts(1))
+		}
+	}
+
+	return otlpmetrichttp.NewClient(httpOpts...), nil
 }
@@ -1,15 +1,29 @@
 package metric_test
 
 import (
+	"bufio"
 	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
 
 	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
 	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/metric"
-	"github.com/stretchr/testify/assert"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/retrytest"
+	"github.com/MovieStoreGuy/otel-go-starter/otelstartertest/collector"
 )
 
 func TestConfiguringExporters(t *testing.T) {
@@ -31,6 +45,7 @@ func TestConfiguringExporters(t *testing.T) {
 		{scenario: "Stdout Exporter", conf: &config.Export{Named: "stdout"}},
 		{scenario: "Basic grpc otlp exporter", conf: &config.Export{Named: "otlpgrpc", Endpoint: s.URL}},
 		{scenario: "Basic http otlp exporter", conf: &config.Export{Named: "otlphttp", Endpoint: s.URL}},
+		{scenario: "Prometheus exporter", conf: &config.Export{Named: "prometheus", Endpoint: "http://localhost:0"}},
 		{
 			scenario: "Configured grpc otlp exporter",
 			conf: &config.Export{
@@ -75,3 +90,119 @@ func TestConfiguringExporters(t *testing.T) {
 	_, err := metric.NewExporterFactory().NewExporter(ctx, &config.Export{Named: "undefined-exporter"})
 	assert.ErrorIs(t, err, metric.ErrNotDefinedExporter)
 }
+
+func TestOTLPExporterRetries(t *testing.T) {
+	t.Parallel()
+
+	retrytest.Run(t, func(t *testing.T, ctx context.Context, conf *config.Export) error {
+		exporter, err := metric.NewExporterFactory().NewExporter(ctx, conf)
+		require.NoError(t, err, "Must not error when configuring exporter")
+
+		cont := controller.New(
+			processor.NewFactory(selector.NewWithInexpensiveDistribution(), exporter),
+			controller.WithCollectPeriod(0),
+		)
+		otelmetric.Must(cont.Meter("test")).NewInt64Counter("retried").Add(ctx, 1)
+		require.NoError(t, cont.Collect(ctx))
+
+		err = exporter.Export(ctx, cont.Resource(), cont)
+		if sh, ok := exporter.(metric.ShutdownExporter); ok {
+			assert.NoError(t, sh.Shutdown(ctx))
+		}
+		return err
+	})
+}
+
+func TestOTLPExportersWithCollector(t *testing.T) {
+	t.Parallel()
+
+	c := collector.New(t)
+
+	testCases := []struct {
+		name     string
+		endpoint string
+		protocol collector.Protocol
+		path     string
+	}{
+		{name: "otlpgrpc", endpoint: c.GRPCEndpoint(), protocol: collector.ProtocolGRPC},
+		{name: "otlphttp", endpoint: c.HTTPEndpoint(), protocol: collector.ProtocolHTTP, path: "/v1/metrics"},
+		{name: "otlphttp", endpoint: c.HTTPEndpoint() + "/gateway/metrics", protocol: collector.ProtocolHTTP, path: "/gateway/metrics"},
+	}
+
+	for i, tc := range testCases {
+		conf := &config.Export{Named: tc.name}
+		require.NoError(t, config.WithExporterEndpoint(tc.endpoint)(conf))
+		require.NoError(t, config.WithExporterHeaders(map[string]string{"Service-Domain": "icecream"})(conf))
+		require.NoError(t, config.WithExporterUseCompression()(conf))
+
+		ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+		exporter, err := metric.NewExporterFactory().NewExporter(ctx, conf)
+		require.NoError(t, err, "Must not error when configuring the %s exporter", tc.name)
+
+		cont := controller.New(
+			processor.NewFactory(selector.NewWithInexpensiveDistribution(), exporter),
+			controller.WithCollectPeriod(0),
+		)
+		otelmetric.Must(cont.Meter("test")).NewInt64Counter(tc.name).Add(ctx, 1)
+		require.NoError(t, cont.Collect(ctx))
+		require.NoError(t, exporter.Export(ctx, cont.Resource(), cont), "Must send the metrics to the collector")
+		if sh, ok := exporter.(metric.ShutdownExporter); ok {
+			assert.NoError(t, sh.Shutdown(ctx))
+		}
+		done()
+
+		requests := c.Requests()
+		require.Len(t, requests, i+1)
+		req := requests[i]
+		assert.Equal(t, tc.protocol, req.Protocol, "Must use the %s endpoint", tc.protocol)
+		assert.Equal(t, collector.SignalMetrics, req.Signal)
+		assert.Equal(t, "icecream", req.Headers.Get("Service-Domain"), "Must send the configured headers")
+		assert.Equal(t, "gzip", req.Compression, "Must compress the request")
+		assert.Equal(t, tc.path, req.Path, "Must send the request to the endpoint's path")
+		if metrics := c.Metrics(); assert.Len(t, metrics, i+1) {
+			assert.Equal(t, tc.name, metrics[i].Name)
+		}
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "metrics.jsonl")
+	conf := &config.Export{Named: "file"}
+	require.NoError(t, config.WithExporterPath(path)(conf))
+	require.NoError(t, config.WithExporterFileMaxSize(1<<20)(conf))
+
+	ctx := context.Background()
+	exporter, err := metric.NewExporterFactory().NewExporter(ctx, conf)
+	require.NoError(t, err, "Must not error when configuring exporter")
+
+	cont := controller.New(
+		processor.NewFactory(selector.NewWithInexpensiveDistribution(), exporter),
+		controller.WithCollectPeriod(0),
+	)
+	otelmetric.Must(cont.Meter("test")).NewInt64Counter("orders").Add(ctx, 3)
+	require.NoError(t, cont.Collect(ctx))
+	require.NoError(t, exporter.Export(ctx, cont.Resource(), cont))
+	if sh, ok := exporter.(metric.ShutdownExporter); assert.True(t, ok, "Must be able to shutdown the file exporter") {
+		require.NoError(t, sh.Shutdown(ctx))
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	require.True(t, scanner.Scan(), "Must write the export")
+
+	var req colmetricspb.ExportMetricsServiceRequest
+	require.NoError(t, otlpjson.Unmarshal(scanner.Bytes(), &req), "Must write OTLP/JSON")
+	require.Len(t, req.ResourceMetrics, 1)
+	m := req.ResourceMetrics[0].InstrumentationLibraryMetrics[0].Metrics[0]
+	assert.Equal(t, "orders", m.Name)
+	assert.Equal(t, int64(3), m.GetSum().DataPoints[0].GetAsInt())
+	assert.False(t, scanner.Scan(), "Must write each export on its own line")
+
+	_, err = metric.NewExporterFactory().NewExporter(ctx, &config.Export{Named: "file"})
+	assert.ErrorIs(t, err, config.ErrInvalidParam, "Must require a path for the file exporter")
+}
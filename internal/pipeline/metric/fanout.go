@@ -0,0 +1,86 @@
+package metric
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/multierr"
+)
+
+// FanoutExporter sends each collection to all of the exporters,
+// an exporter failing does not stop the remaining exporters from being called.
+type FanoutExporter []sdkmetric.Exporter
+
+var (
+	_ ShutdownExporter = (FanoutExporter)(nil)
+	_ SelectorExporter = (FanoutExporter)(nil)
+	_ MemoryExporter   = (FanoutExporter)(nil)
+)
+
+// Export sends the collection to the exporters concurrently so that a slow exporter
+// does not use up the push timeout of the others,
+// each exporter's context is cancelled once its export has returned.
+func (fe FanoutExporter) Export(ctx context.Context, res *resource.Resource, reader sdkmetric.InstrumentationLibraryReader) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(fe))
+	)
+	for i, e := range fe {
+		wg.Add(1)
+		go func(i int, e sdkmetric.Exporter) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			errs[i] = e.Export(ctx, res, reader)
+		}(i, e)
+	}
+	wg.Wait()
+	return multierr.Combine(errs...)
+}
+
+// ExportKindFor combines the export kinds of all the exporters
+// so that the processor is able to provide each exporter its required kind.
+func (fe FanoutExporter) ExportKindFor(desc *metric.Descriptor, kind aggregation.Kind) (ek sdkmetric.ExportKind) {
+	for _, e := range fe {
+		ek |= e.ExportKindFor(desc, kind)
+	}
+	return ek
+}
+
+// AggregatorSelector returns the selector of the first exporter that requires one,
+// or nil when none of the exporters require a specific selector.
+func (fe FanoutExporter) AggregatorSelector() sdkmetric.AggregatorSelector {
+	for _, e := range fe {
+		if se, ok := e.(SelectorExporter); ok {
+			if selector := se.AggregatorSelector(); selector != nil {
+				return selector
+			}
+		}
+	}
+	return nil
+}
+
+// RequiresMemory returns true when any of the exporters require memory,
+// the other exporters are then also given the instruments that were not updated
+func (fe FanoutExporter) RequiresMemory() bool {
+	for _, e := range fe {
+		if me, ok := e.(MemoryExporter); ok && me.RequiresMemory() {
+			return true
+		}
+	}
+	return false
+}
+
+func (fe FanoutExporter) Shutdown(ctx context.Context) (err error) {
+	for _, e := range fe {
+		if sh, ok := e.(ShutdownExporter); ok {
+			err = multierr.Append(err, sh.Shutdown(ctx))
+		}
+	}
+	return err
+}
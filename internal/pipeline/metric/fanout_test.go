@@ -0,0 +1,95 @@
+package metric_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	pipeline "github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/metric"
+)
+
+type recordingExporter struct {
+	kind     sdkmetric.ExportKind
+	err      error
+	exported int
+	shutdown int
+}
+
+func (re *recordingExporter) Export(context.Context, *resource.Resource, sdkmetric.InstrumentationLibraryReader) error {
+	re.exported++
+	return re.err
+}
+
+func (re *recordingExporter) ExportKindFor(*metric.Descriptor, aggregation.Kind) sdkmetric.ExportKind {
+	return re.kind
+}
+
+func (re *recordingExporter) Shutdown(context.Context) error {
+	re.shutdown++
+	return re.err
+}
+
+func TestFanoutExporter(t *testing.T) {
+	t.Parallel()
+
+	failure := errors.New("broken backend")
+	var (
+		broken  = &recordingExporter{kind: sdkmetric.DeltaExportKind, err: failure}
+		healthy = &recordingExporter{kind: sdkmetric.CumulativeExportKind}
+		fanout  = pipeline.FanoutExporter{broken, healthy}
+	)
+
+	err := fanout.Export(context.Background(), resource.Empty(), nil)
+	assert.ErrorIs(t, err, failure, "Must return the failed exporter's error")
+	assert.Equal(t, 1, broken.exported)
+	assert.Equal(t, 1, healthy.exported, "Must export to all exporters regardless of failures")
+
+	kind := fanout.ExportKindFor(nil, aggregation.SumKind)
+	assert.True(t, kind.Includes(sdkmetric.DeltaExportKind))
+	assert.True(t, kind.Includes(sdkmetric.CumulativeExportKind))
+
+	assert.ErrorIs(t, fanout.Shutdown(context.Background()), failure)
+	assert.Equal(t, 1, broken.shutdown)
+	assert.Equal(t, 1, healthy.shutdown, "Must shutdown all exporters regardless of failures")
+}
+
+// waitingExporter blocks until the other exporter has been called
+type waitingExporter struct {
+	recordingExporter
+	called chan struct{}
+	wait   bool
+}
+
+func (we *waitingExporter) Export(ctx context.Context, _ *resource.Resource, _ sdkmetric.InstrumentationLibraryReader) error {
+	if !we.wait {
+		close(we.called)
+		return nil
+	}
+	select {
+	case <-we.called:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestFanoutExporterConcurrent(t *testing.T) {
+	t.Parallel()
+
+	called := make(chan struct{})
+	fanout := pipeline.FanoutExporter{
+		&waitingExporter{called: called, wait: true},
+		&waitingExporter{called: called},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, fanout.Export(ctx, resource.Empty(), nil), "Must not wait for the first exporter before calling the next")
+}
@@ -0,0 +1,49 @@
+package metric
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/rotate"
+)
+
+// fileClient writes each upload as a line of OTLP/JSON,
+// allowing the OTLP exporter to be used without a collector
+type fileClient struct {
+	path string
+	conf config.File
+	f    *rotate.File
+}
+
+var _ otlpmetric.Client = (*fileClient)(nil)
+
+func newFileClient(pipe *config.Export) (otlpmetric.Client, error) {
+	if pipe.Path == "" {
+		return nil, fmt.Errorf("file exporter requires a path: %w", config.ErrInvalidParam)
+	}
+	return &fileClient{path: pipe.Path, conf: pipe.File}, nil
+}
+
+func (fc *fileClient) Start(context.Context) (err error) {
+	fc.f, err = rotate.Open(fc.path, fc.conf)
+	return err
+}
+
+func (fc *fileClient) Stop(context.Context) error {
+	return fc.f.Close()
+}
+
+func (fc *fileClient) UploadMetrics(_ context.Context, metrics []*metricspb.ResourceMetrics) error {
+	b, err := otlpjson.Marshal(&colmetricspb.ExportMetricsServiceRequest{ResourceMetrics: metrics})
+	if err != nil {
+		return err
+	}
+	_, err = fc.f.Write(append(b, '\n'))
+	return err
+}
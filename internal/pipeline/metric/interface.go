@@ -13,3 +13,20 @@ type ShutdownExporter interface {
 
 	Shutdown(ctx context.Context) error
 }
+
+// SelectorExporter is used by exporters that require
+// specific aggregations to be used for their instruments.
+type SelectorExporter interface {
+	sdkmetric.Exporter
+
+	AggregatorSelector() sdkmetric.AggregatorSelector
+}
+
+// MemoryExporter is used by exporters that must be given every instrument
+// that has been recorded, not only those updated since the last collection,
+// ie a pull exporter that keeps serving each series it has seen.
+type MemoryExporter interface {
+	sdkmetric.Exporter
+
+	RequiresMemory() bool
+}
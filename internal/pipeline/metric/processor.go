@@ -0,0 +1,22 @@
+package metric
+
+import (
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+)
+
+// NewProcessorFactory creates the processor used by the controller for the exporter,
+// using the exporter's aggregator selector and memory requirements when it defines them
+func NewProcessorFactory(exporter sdkmetric.Exporter) sdkmetric.CheckpointerFactory {
+	aggregators := selector.NewWithInexpensiveDistribution()
+	if se, ok := exporter.(SelectorExporter); ok && se.AggregatorSelector() != nil {
+		aggregators = se.AggregatorSelector()
+	}
+
+	var opts []processor.Option
+	if me, ok := exporter.(MemoryExporter); ok && me.RequiresMemory() {
+		opts = append(opts, processor.WithMemory(true))
+	}
+	return processor.NewFactory(aggregators, exporter, opts...)
+}
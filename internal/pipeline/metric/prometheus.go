@@ -0,0 +1,470 @@
+package metric
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+)
+
+// ErrNameCollision is returned when instruments or labels that have different names
+// are exposed using the same name once they have been sanitized, ie `http.requests`
+// and `http_requests`. The colliding records are not exposed.
+var ErrNameCollision = errors.New("prometheus name collision")
+
+const (
+	// PrometheusPath is the path the exporter serves metrics on
+	PrometheusPath = "/metrics"
+
+	// prometheusAddress is the default listen address defined
+	// by OTEL_EXPORTER_PROMETHEUS_HOST and OTEL_EXPORTER_PROMETHEUS_PORT
+	prometheusAddress = "localhost:9464"
+
+	prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// PrometheusExporter serves the most recent collection in the
+// Prometheus text exposition format, the metrics are cumulative
+// so each scrape reports the values as of the last collection period.
+type PrometheusExporter struct {
+	mu      sync.RWMutex
+	payload []byte
+	// closed stops the handler serving once the exporter is shutdown,
+	// since a handler registered on a mux is not able to be removed
+	closed bool
+
+	server *http.Server
+	addr   string
+}
+
+var (
+	_ ShutdownExporter = (*PrometheusExporter)(nil)
+	_ SelectorExporter = (*PrometheusExporter)(nil)
+	_ MemoryExporter   = (*PrometheusExporter)(nil)
+	_ http.Handler     = (*PrometheusExporter)(nil)
+)
+
+// NewPrometheusExporter creates the exporter and serves it on the configured endpoint,
+// unless a mux has been provided in which case the handler is registered on the mux.
+// The handler remains registered after the exporter is shutdown, so a mux is not able
+// to be reused by another exporter and an error is returned when the path is in use.
+func NewPrometheusExporter(pipe *config.Export) (*PrometheusExporter, error) {
+	pe := &PrometheusExporter{}
+	if pipe.Mux != nil {
+		// http.ServeMux panics when a pattern is registered twice,
+		// ie a launcher being started again using the same mux
+		if m, ok := pipe.Mux.(interface {
+			Handler(r *http.Request) (http.Handler, string)
+		}); ok {
+			if _, pattern := m.Handler(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: PrometheusPath}}); pattern == PrometheusPath {
+				return nil, fmt.Errorf("%s is already registered on the mux: %w", PrometheusPath, config.ErrInvalidParam)
+			}
+		}
+		pipe.Mux.Handle(PrometheusPath, pe)
+		return pe, nil
+	}
+
+	addr := prometheusAddress
+	if pipe.Endpoint != "" {
+		u, err := url.Parse(pipe.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		addr = u.Host
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(PrometheusPath, pe)
+	pe.server, pe.addr = &http.Server{Handler: mux}, l.Addr().String()
+	go func() {
+		// Serve always returns an error once the server is shutdown
+		_ = pe.server.Serve(l)
+	}()
+	return pe, nil
+}
+
+// Addr returns the address the exporter is serving on,
+// or an empty string if the handler is mounted on a mux.
+func (pe *PrometheusExporter) Addr() string {
+	return pe.addr
+}
+
+func (pe *PrometheusExporter) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	if pe.closed {
+		http.Error(rw, "exporter is shutdown", http.StatusServiceUnavailable)
+		return
+	}
+	rw.Header().Set("Content-Type", prometheusContentType)
+	_, _ = rw.Write(pe.payload)
+}
+
+// ExportKindFor returns cumulative since Prometheus expects
+// counters and histograms to increase between scrapes
+func (pe *PrometheusExporter) ExportKindFor(_ *metric.Descriptor, _ aggregation.Kind) sdkmetric.ExportKind {
+	return sdkmetric.CumulativeExportKind
+}
+
+// AggregatorSelector uses histograms for the histogram instruments
+// so that they can be exposed with their buckets
+func (pe *PrometheusExporter) AggregatorSelector() sdkmetric.AggregatorSelector {
+	return simple.NewWithHistogramDistribution()
+}
+
+// RequiresMemory returns true so that the series that have not been updated
+// during the last collection period continue to be served
+func (pe *PrometheusExporter) RequiresMemory() bool {
+	return true
+}
+
+// Export replaces the exposed metrics with the collection,
+// records that collide with another record are skipped and returned as an error
+func (pe *PrometheusExporter) Export(_ context.Context, res *resource.Resource, reader sdkmetric.InstrumentationLibraryReader) error {
+	var (
+		buf        bytes.Buffer
+		collisions error
+		families   = newPromFamilies()
+	)
+	if err := writeTargetInfo(&buf, res); err != nil {
+		collisions = multierr.Append(collisions, err)
+	} else if res != nil && res.Len() != 0 {
+		families.series[targetInfo] = "the resource"
+	}
+
+	err := reader.ForEach(func(_ instrumentation.Library, r sdkmetric.Reader) error {
+		return r.ForEach(pe, func(rec sdkmetric.Record) error {
+			err := families.add(rec)
+			if errors.Is(err, ErrNameCollision) {
+				collisions = multierr.Append(collisions, err)
+				return nil
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(families.byName))
+	for name := range families.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		families.byName[name].write(&buf, name)
+	}
+
+	pe.mu.Lock()
+	pe.payload = buf.Bytes()
+	pe.mu.Unlock()
+	return collisions
+}
+
+func (pe *PrometheusExporter) Shutdown(ctx context.Context) error {
+	pe.mu.Lock()
+	pe.closed, pe.payload = true, nil
+	pe.mu.Unlock()
+
+	if pe.server == nil {
+		return nil
+	}
+	if err := pe.server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// targetInfo is the name of the metric holding the resource attributes
+const targetInfo = "target_info"
+
+// promFamily holds the samples of a single metric name
+type promFamily struct {
+	typ     string
+	help    string
+	samples []string
+	// instrument is the name of the instrument before it was sanitized
+	instrument string
+}
+
+func (f *promFamily) write(buf *bytes.Buffer, name string) {
+	if f.help != "" {
+		fmt.Fprintf(buf, "# HELP %s %s\n", name, escapeHelp(f.help))
+	}
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, f.typ)
+	for _, s := range f.samples {
+		buf.WriteString(s)
+	}
+}
+
+// promFamilies are the families of a collection by their sanitized name
+type promFamilies struct {
+	byName map[string]*promFamily
+	// series maps each exposed series name to the instrument that it belongs to,
+	// ie the _bucket, _sum and _count series of a histogram
+	series map[string]string
+}
+
+func newPromFamilies() *promFamilies {
+	return &promFamilies{
+		byName: make(map[string]*promFamily),
+		series: make(map[string]string),
+	}
+}
+
+// family returns the family of the instrument, the family is created along with
+// its series, named using the suffixes, unless they collide with another instrument
+func (pf *promFamilies) family(name, typ string, desc *metric.Descriptor, suffixes ...string) (*promFamily, error) {
+	if f, ok := pf.byName[name]; ok {
+		if f.instrument != desc.Name() || f.typ != typ {
+			return nil, fmt.Errorf("%s %s from %s collides with %s %s: %w", typ, name, desc.Name(), f.typ, f.instrument, ErrNameCollision)
+		}
+		return f, nil
+	}
+
+	if len(suffixes) == 0 {
+		suffixes = []string{""}
+	}
+	for _, suffix := range suffixes {
+		if owner, ok := pf.series[name+suffix]; ok {
+			return nil, fmt.Errorf("%s from %s collides with %s: %w", name+suffix, desc.Name(), owner, ErrNameCollision)
+		}
+	}
+	for _, suffix := range suffixes {
+		pf.series[name+suffix] = desc.Name()
+	}
+	f := &promFamily{typ: typ, help: desc.Description(), instrument: desc.Name()}
+	pf.byName[name] = f
+	return f, nil
+}
+
+func (pf *promFamilies) add(rec sdkmetric.Record) error {
+	desc := rec.Descriptor()
+	name := sanitizeName(desc.Name())
+	labels := rec.Labels().ToSlice()
+
+	// The labels are checked before the family is created
+	// so that a rejected record does not expose an empty family
+	reserved := ""
+	if _, ok := rec.Aggregation().(aggregation.Histogram); ok {
+		reserved = "le"
+	}
+	if err := checkLabels(labels, reserved); err != nil {
+		return fmt.Errorf("%s: %w", desc.Name(), err)
+	}
+
+	var (
+		f       *promFamily
+		samples []string
+	)
+	switch agg := rec.Aggregation().(type) {
+	case aggregation.Histogram:
+		buckets, err := agg.Histogram()
+		if err != nil {
+			return err
+		}
+		count, err := agg.Count()
+		if err != nil {
+			return err
+		}
+		sum, err := agg.Sum()
+		if err != nil {
+			return err
+		}
+		if f, err = pf.family(name, "histogram", desc, "_bucket", "_sum", "_count"); err != nil {
+			return err
+		}
+		var cumulative uint64
+		for i, bound := range buckets.Boundaries {
+			cumulative += buckets.Counts[i]
+			samples = append(samples, sample(name+"_bucket", labels, formatFloat(float64(cumulative)), "le", formatFloat(bound)))
+		}
+		samples = append(samples,
+			sample(name+"_bucket", labels, strconv.FormatUint(count, 10), "le", "+Inf"),
+			sample(name+"_sum", labels, formatFloat(sum.CoerceToFloat64(desc.NumberKind()))),
+			sample(name+"_count", labels, strconv.FormatUint(count, 10)),
+		)
+	case aggregation.MinMaxSumCount:
+		count, err := agg.Count()
+		if err != nil {
+			return err
+		}
+		sum, err := agg.Sum()
+		if err != nil {
+			return err
+		}
+		if f, err = pf.family(name, "summary", desc, "_sum", "_count"); err != nil {
+			return err
+		}
+		samples = append(samples,
+			sample(name+"_sum", labels, formatFloat(sum.CoerceToFloat64(desc.NumberKind()))),
+			sample(name+"_count", labels, strconv.FormatUint(count, 10)),
+		)
+	case aggregation.Sum:
+		sum, err := agg.Sum()
+		if err != nil {
+			return err
+		}
+		value := formatFloat(sum.CoerceToFloat64(desc.NumberKind()))
+		switch desc.InstrumentKind() {
+		case sdkapi.CounterInstrumentKind, sdkapi.CounterObserverInstrumentKind:
+			if !strings.HasSuffix(name, "_total") {
+				name += "_total"
+			}
+			f, err = pf.family(name, "counter", desc)
+		default:
+			// Up down counters are able to decrease so are exposed as gauges
+			f, err = pf.family(name, "gauge", desc)
+		}
+		if err != nil {
+			return err
+		}
+		samples = append(samples, sample(name, labels, value))
+	case aggregation.LastValue:
+		last, _, err := agg.LastValue()
+		if err != nil {
+			return err
+		}
+		if f, err = pf.family(name, "gauge", desc); err != nil {
+			return err
+		}
+		samples = append(samples, sample(name, labels, formatFloat(last.CoerceToFloat64(desc.NumberKind()))))
+	default:
+		return fmt.Errorf("unsupported aggregation %s for %s: %w", agg.Kind(), desc.Name(), aggregation.ErrInconsistentType)
+	}
+
+	f.samples = append(f.samples, samples...)
+	return nil
+}
+
+// checkLabels returns an error when labels are exposed using the same name,
+// or the name reserved by the metric type once they have been sanitized
+func checkLabels(labels []attribute.KeyValue, reserved string) error {
+	seen := make(map[string]attribute.Key, len(labels))
+	if reserved != "" {
+		seen[reserved] = attribute.Key(reserved)
+	}
+	for _, kv := range labels {
+		name := sanitizeLabel(string(kv.Key))
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("label %s from %s collides with %s: %w", name, kv.Key, prev, ErrNameCollision)
+		}
+		seen[name] = kv.Key
+	}
+	return nil
+}
+
+// writeTargetInfo exposes the resource attributes as the labels of
+// the target_info metric, as defined by the Open Telemetry specification
+func writeTargetInfo(buf *bytes.Buffer, res *resource.Resource) error {
+	if res == nil || res.Len() == 0 {
+		return nil
+	}
+	if err := checkLabels(res.Attributes(), ""); err != nil {
+		return fmt.Errorf("%s: %w", targetInfo, err)
+	}
+	buf.WriteString("# HELP target_info Target metadata\n")
+	buf.WriteString("# TYPE target_info gauge\n")
+	buf.WriteString(sample(targetInfo, res.Attributes(), "1"))
+	return nil
+}
+
+func sample(name string, labels []attribute.KeyValue, value string, extra ...string) string {
+	var b strings.Builder
+	b.WriteString(name)
+
+	pairs := make([]string, 0, len(labels)+len(extra)/2)
+	for _, kv := range labels {
+		pairs = append(pairs, sanitizeLabel(string(kv.Key))+`="`+escapeLabelValue(kv.Value.Emit())+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escapeLabelValue(extra[i+1])+`"`)
+	}
+	if len(pairs) != 0 {
+		b.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+	b.WriteString(" " + value + "\n")
+	return b.String()
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sanitizeName replaces the characters not allowed within a metric name,
+// ie `http.server.duration` becomes `http_server_duration`
+func sanitizeName(name string) string {
+	return sanitize(name, true)
+}
+
+func sanitizeLabel(name string) string {
+	return sanitize(name, false)
+}
+
+func sanitize(name string, allowColon bool) string {
+	var b strings.Builder
+	for _, r := range name {
+		valid := r == '_' ||
+			(r >= 'a' && r <= 'z') ||
+			(r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9') ||
+			(r == ':' && allowColon)
+		if !valid {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "_"
+	}
+	if c := name[0]; c >= '0' && c <= '9' {
+		return "_" + b.String()
+	}
+	return b.String()
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
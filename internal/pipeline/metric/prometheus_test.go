@@ -0,0 +1,229 @@
+package metric_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/metric"
+)
+
+func TestPrometheusExporterFormat(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mux := http.NewServeMux()
+	exporter, err := metric.NewPrometheusExporter(&config.Export{Named: "prometheus", Mux: mux})
+	require.NoError(t, err, "Must not error when using a mux")
+	assert.Empty(t, exporter.Addr(), "Must not listen when using a mux")
+
+	res := resource.NewSchemaless(
+		attribute.String("service.name", "checkout"),
+		attribute.String("team", `payments "squad"`),
+	)
+	cont := controller.New(
+		processor.NewFactory(exporter.AggregatorSelector(), exporter),
+		controller.WithResource(res),
+		controller.WithCollectPeriod(0),
+	)
+
+	meter := otelmetric.Must(cont.Meter("test"))
+	meter.NewInt64Counter("http.requests", otelmetric.WithDescription("Number of requests")).
+		Add(ctx, 3, attribute.String("method", "GET"))
+	meter.NewInt64UpDownCounter("queue.depth").
+		Add(ctx, -2)
+	meter.NewFloat64Histogram("http.duration").
+		Record(ctx, 0.3)
+
+	require.NoError(t, cont.Collect(ctx), "Must not error when collecting")
+	require.NoError(t, exporter.Export(ctx, res, cont), "Must not error when exporting")
+
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	resp, err := http.Get(s.URL + metric.PrometheusPath)
+	require.NoError(t, err, "Must not error when scraping")
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "Must not error reading the response")
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+	for _, expect := range []string{
+		"# TYPE target_info gauge\n",
+		`target_info{service_name="checkout",team="payments \"squad\""} 1` + "\n",
+		"# HELP http_requests_total Number of requests\n",
+		"# TYPE http_requests_total counter\n",
+		`http_requests_total{method="GET"} 3` + "\n",
+		"# TYPE queue_depth gauge\n",
+		"queue_depth -2\n",
+		"# TYPE http_duration histogram\n",
+		`http_duration_bucket{le="0.25"} 0` + "\n",
+		`http_duration_bucket{le="0.5"} 1` + "\n",
+		`http_duration_bucket{le="+Inf"} 1` + "\n",
+		"http_duration_sum 0.3\n",
+		"http_duration_count 1\n",
+	} {
+		assert.Contains(t, string(body), expect)
+	}
+}
+
+func TestPrometheusExporterListens(t *testing.T) {
+	t.Parallel()
+
+	exporter, err := metric.NewPrometheusExporter(&config.Export{Named: "prometheus", Endpoint: "http://localhost:0"})
+	require.NoError(t, err, "Must not error when listening")
+	t.Cleanup(func() {
+		assert.NoError(t, exporter.Shutdown(context.Background()), "Must not error when shutting down")
+	})
+
+	resp, err := http.Get("http://" + exporter.Addr() + metric.PrometheusPath)
+	require.NoError(t, err, "Must be able to scrape the exporter")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = metric.NewPrometheusExporter(&config.Export{Named: "prometheus", Endpoint: "http://" + exporter.Addr()})
+	assert.Error(t, err, "Must error when the address is in use")
+}
+
+func TestPrometheusExporterCollisions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario string
+		record   func(ctx context.Context, meter otelmetric.MeterMust)
+		message  string
+	}{
+		{
+			scenario: "Instrument names",
+			record: func(ctx context.Context, meter otelmetric.MeterMust) {
+				meter.NewInt64Counter("http.requests").Add(ctx, 1)
+				meter.NewInt64Counter("http_requests").Add(ctx, 1)
+			},
+			message: "http_requests_total",
+		},
+		{
+			scenario: "Instrument kinds",
+			record: func(ctx context.Context, meter otelmetric.MeterMust) {
+				meter.NewInt64UpDownCounter("queue.depth").Add(ctx, 1)
+				meter.NewFloat64Histogram("queue_depth").Record(ctx, 1)
+			},
+			message: "queue_depth",
+		},
+		{
+			scenario: "Histogram series",
+			record: func(ctx context.Context, meter otelmetric.MeterMust) {
+				meter.NewFloat64Histogram("http.duration").Record(ctx, 1)
+				meter.NewInt64UpDownCounter("http.duration.count").Add(ctx, 1)
+			},
+			message: "http_duration_count",
+		},
+		{
+			scenario: "Label names",
+			record: func(ctx context.Context, meter otelmetric.MeterMust) {
+				meter.NewInt64Counter("http.requests").Add(ctx, 1,
+					attribute.String("http.method", "GET"),
+					attribute.String("http_method", "POST"),
+				)
+			},
+			message: "label http_method",
+		},
+		{
+			scenario: "Reserved label",
+			record: func(ctx context.Context, meter otelmetric.MeterMust) {
+				meter.NewFloat64Histogram("http.duration").Record(ctx, 1, attribute.String("le", "1"))
+			},
+			message: "label le",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			mux := http.NewServeMux()
+			exporter, err := metric.NewPrometheusExporter(&config.Export{Named: "prometheus", Mux: mux})
+			require.NoError(t, err)
+
+			cont := controller.New(
+				processor.NewFactory(exporter.AggregatorSelector(), exporter),
+				controller.WithCollectPeriod(0),
+			)
+			tc.record(ctx, otelmetric.Must(cont.Meter("test")))
+			require.NoError(t, cont.Collect(ctx))
+
+			err = exporter.Export(ctx, resource.Empty(), cont)
+			assert.ErrorIs(t, err, metric.ErrNameCollision, "Must report the collision")
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.message)
+			}
+		})
+	}
+}
+
+func TestPrometheusExporterIdleSeries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	exporter, err := metric.NewPrometheusExporter(&config.Export{Named: "prometheus", Mux: mux})
+	require.NoError(t, err)
+
+	cont := controller.New(
+		metric.NewProcessorFactory(exporter),
+		controller.WithCollectPeriod(0),
+	)
+	otelmetric.Must(cont.Meter("test")).NewInt64Counter("http.requests").Add(ctx, 3)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, cont.Collect(ctx), "Must not error when collecting")
+		require.NoError(t, exporter.Export(ctx, resource.Empty(), cont), "Must not error when exporting")
+	}
+
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	resp, err := http.Get(s.URL + metric.PrometheusPath)
+	require.NoError(t, err, "Must not error when scraping")
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "Must not error reading the response")
+	assert.Contains(t, string(body), "http_requests_total 3\n", "Must keep serving series that were not updated")
+}
+
+func TestPrometheusExporterShutdown(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	exporter, err := metric.NewPrometheusExporter(&config.Export{Named: "prometheus", Mux: mux})
+	require.NoError(t, err)
+
+	_, err = metric.NewPrometheusExporter(&config.Export{Named: "prometheus", Mux: mux})
+	assert.ErrorIs(t, err, config.ErrInvalidParam, "Must error when the mux is reused")
+
+	require.NoError(t, exporter.Shutdown(context.Background()), "Must not error when shutting down")
+
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	resp, err := http.Get(s.URL + metric.PrometheusPath)
+	require.NoError(t, err, "Must not error when scraping")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode, "Must not serve once shutdown")
+}
@@ -0,0 +1,104 @@
+package metric
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+)
+
+// Pusher collects and exports the controller's metrics once each period.
+// Unlike starting the controller, the metrics are able to be collected and
+// exported on demand while it is running so they can be flushed.
+//
+// The controller must be created without an exporter and with a collect period of zero,
+// so that each call to Collect is not skipped.
+type Pusher struct {
+	controller *controller.Controller
+	exporter   sdkmetric.Exporter
+	period     time.Duration
+	timeout    time.Duration
+	handler    otel.ErrorHandler
+
+	// mu stops the periodic and forced collections from running at the same time
+	mu sync.Mutex
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPusher starts collecting from the controller once each period,
+// each export is limited to the timeout when it is greater than zero.
+// Errors from the periodic exports are sent to the handler,
+// the global error handler is used when it is nil.
+func NewPusher(c *controller.Controller, exporter sdkmetric.Exporter, period, timeout time.Duration, handler otel.ErrorHandler) *Pusher {
+	if period <= 0 {
+		period = controller.DefaultPeriod
+	}
+	if handler == nil {
+		handler = otel.GetErrorHandler()
+	}
+	p := &Pusher{
+		controller: c,
+		exporter:   exporter,
+		period:     period,
+		timeout:    timeout,
+		handler:    handler,
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Collect checkpoints the controller's metrics and exports them
+func (p *Pusher) Collect(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.controller.Collect(ctx); err != nil {
+		return err
+	}
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+	return p.exporter.Export(ctx, p.controller.Resource(), p.controller)
+}
+
+// Stop ends the periodic collections and then performs a final collection,
+// it is safe to call more than once
+func (p *Pusher) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return p.Collect(ctx)
+}
+
+func (p *Pusher) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.Collect(context.Background()); err != nil {
+				p.handler.Handle(err)
+			}
+		case <-p.stop:
+			return
+		}
+	}
+}
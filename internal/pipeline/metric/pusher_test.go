@@ -0,0 +1,66 @@
+package metric_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/metric"
+)
+
+// countingExporter counts its exports and the records within them
+type countingExporter struct {
+	exports int64
+	records int64
+}
+
+func (ce *countingExporter) Export(_ context.Context, _ *resource.Resource, reader sdkmetric.InstrumentationLibraryReader) error {
+	atomic.AddInt64(&ce.exports, 1)
+	return reader.ForEach(func(_ instrumentation.Library, r sdkmetric.Reader) error {
+		return r.ForEach(ce, func(sdkmetric.Record) error {
+			atomic.AddInt64(&ce.records, 1)
+			return nil
+		})
+	})
+}
+
+func (ce *countingExporter) ExportKindFor(*otelmetric.Descriptor, aggregation.Kind) sdkmetric.ExportKind {
+	return sdkmetric.CumulativeExportKind
+}
+
+func TestPusher(t *testing.T) {
+	t.Parallel()
+
+	exporter := &countingExporter{}
+	cont := controller.New(
+		processor.NewFactory(simple.NewWithInexpensiveDistribution(), exporter),
+		controller.WithCollectPeriod(0),
+	)
+	pusher := metric.NewPusher(cont, exporter, 10*time.Millisecond, time.Second, nil)
+
+	otelmetric.Must(cont.Meter("test")).NewInt64Counter("requests").Add(context.Background(), 1)
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt64(&exporter.exports) >= 2
+	}, 5*time.Second, 10*time.Millisecond, "Must export once each period")
+
+	require.NoError(t, pusher.Collect(context.Background()), "Must collect while running")
+	assert.GreaterOrEqual(t, atomic.LoadInt64(&exporter.records), int64(1), "Must export the recorded metrics")
+
+	require.NoError(t, pusher.Stop(context.Background()))
+	exports := atomic.LoadInt64(&exporter.exports)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, exports, atomic.LoadInt64(&exporter.exports), "Must not export once stopped")
+}
@@ -0,0 +1,72 @@
+// Package retrytest checks that the OTLP exporters of each pipeline
+// apply the configured retry settings in the same way.
+package retrytest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+)
+
+// failures is the number of requests rejected before the server recovers
+const failures = 2
+
+// ExportFunc creates an exporter from the configuration, exports to it
+// and shuts it down, returning the error of the export
+type ExportFunc func(t *testing.T, ctx context.Context, conf *config.Export) error
+
+// Run exports to an OTLP/HTTP server that is unavailable for the first requests,
+// checking that retries continue until the server recovers and that the data
+// is dropped after the first attempt when retries are disabled
+func Run(t *testing.T, export ExportFunc) {
+	t.Helper()
+
+	var attempts int64
+	s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if atomic.AddInt64(&attempts, 1) <= failures {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+
+	testCases := []struct {
+		scenario string
+		opt      config.ExportOption
+		attempts int64
+		success  bool
+	}{
+		{scenario: "Retries until the export succeeds", opt: config.WithExporterRetry(time.Millisecond, 10*time.Millisecond, time.Second), attempts: failures + 1, success: true},
+		{scenario: "Drops data when retries are disabled", opt: config.WithExporterRetryDisabled(), attempts: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			atomic.StoreInt64(&attempts, 0)
+
+			conf := &config.Export{Named: "otlphttp"}
+			require.NoError(t, config.WithExporterEndpoint(s.URL)(conf))
+			require.NoError(t, config.WithExporterTimeout(time.Second)(conf))
+			require.NoError(t, tc.opt(conf))
+
+			err := export(t, context.Background(), conf)
+			if tc.success {
+				assert.NoError(t, err, "Must export once the server recovers")
+			} else {
+				assert.Error(t, err, "Must return the failed export")
+			}
+			assert.Equal(t, tc.attempts, atomic.LoadInt64(&attempts))
+		})
+	}
+}
@@ -2,15 +2,21 @@ package trace
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/http"
+	"sort"
+	"strings"
 
 	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/exporters/zipkin"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/encoding/gzip"
 
 	"github.com/MovieStoreGuy/otel-go-starter/config"
@@ -27,59 +33,163 @@ type generatorFunc func(ctx context.Context, conf *config.Export) (sdktrace.Span
 func (ef ExporterFactory) NewExporter(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
 	factory, exist := (ef)[conf.Named]
 	if !exist {
-		return nil, fmt.Errorf("unknown exporter %s: %w", conf.Named, ErrNotDefinedExporter)
+		return nil, fmt.Errorf("unknown exporter %s, must be one of [%s]: %w", conf.Named, strings.Join(ef.Names(), ", "), ErrNotDefinedExporter)
 	}
 	return factory(ctx, conf)
 }
 
+// Names returns the sorted names of the exporters within the factory
+func (ef ExporterFactory) Names() []string {
+	names := make([]string, 0, len(ef))
+	for name := range ef {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func NewExporterFactory() ExporterFactory {
 	return map[string]generatorFunc{
-		"otlpgrpc": func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
-			var grpcOpts []otlptracegrpc.Option
-
-			if endpoint := conf.Endpoint; endpoint != "" {
-				grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpoint(endpoint))
-			}
-			if headers := conf.Headers; len(headers) != 0 {
-				grpcOpts = append(grpcOpts, otlptracegrpc.WithHeaders(headers))
-			}
-			if conf.AllowInsecure {
-				grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
+		"otlpgrpc": newOTLPExporter,
+		"otlphttp": newOTLPExporter,
+		"zipkin": func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
+			tlsConf, err := conf.TLS.ClientConfig()
+			if err != nil {
+				return nil, err
 			}
-			if conf.UseCompression {
-				grpcOpts = append(grpcOpts, otlptracegrpc.WithCompressor(gzip.Name))
+
+			var zipkinOpts []zipkin.Option
+			if tlsConf != nil {
+				zipkinOpts = append(zipkinOpts, zipkin.WithClient(newHTTPClient(tlsConf)))
 			}
 
-			return otlptracegrpc.New(ctx, grpcOpts...)
+			return zipkin.New(conf.Endpoint, zipkinOpts...)
 		},
-		"otlphttp": func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
-			var httpOpts []otlptracehttp.Option
-
-			if endpoint := conf.Endpoint; endpoint != "" {
-				httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(endpoint))
-			}
-			if headers := conf.Headers; len(headers) != 0 {
-				httpOpts = append(httpOpts, otlptracehttp.WithHeaders(headers))
+		"jaeger": func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
+			tlsConf, err := conf.TLS.ClientConfig()
+			if err != nil {
+				return nil, err
 			}
-			if conf.AllowInsecure {
-				httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+
+			collectorOpts := []jaeger.CollectorEndpointOption{
+				jaeger.WithEndpoint(conf.Endpoint),
 			}
-			if conf.UseCompression {
-				httpOpts = append(httpOpts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+			if tlsConf != nil {
+				collectorOpts = append(collectorOpts, jaeger.WithHTTPClient(newHTTPClient(tlsConf)))
 			}
 
-			return otlptracehttp.New(ctx, httpOpts...)
-		},
-		"zipkin": func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
-			return zipkin.New(conf.Endpoint)
-		},
-		"jaeger": func(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
-			return jaeger.New(jaeger.WithCollectorEndpoint(
-				jaeger.WithEndpoint(conf.Endpoint),
-			))
+			return jaeger.New(jaeger.WithCollectorEndpoint(collectorOpts...))
 		},
 		"stdout": func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
 			return stdouttrace.New(stdouttrace.WithPrettyPrint())
 		},
+		"file": newOTLPExporter,
 	}
 }
+
+// NewClient creates the OTLP client used by the otlpgrpc, otlphttp and file exporters,
+// allowing OTLP payloads to be sent without converting them from spans
+func NewClient(conf *config.Export) (otlptrace.Client, error) {
+	switch conf.Named {
+	case "otlpgrpc":
+		return newOTLPGRPCClient(conf)
+	case "otlphttp":
+		return newOTLPHTTPClient(conf)
+	case "file":
+		return newFileClient(conf)
+	}
+	return nil, fmt.Errorf("exporter %s does not send OTLP payloads: %w", conf.Named, ErrNotDefinedExporter)
+}
+
+func newOTLPExporter(ctx context.Context, conf *config.Export) (sdktrace.SpanExporter, error) {
+	client, err := NewClient(conf)
+	if err != nil {
+		return nil, err
+	}
+	return otlptrace.New(ctx, client)
+}
+
+func newOTLPGRPCClient(conf *config.Export) (otlptrace.Client, error) {
+	var grpcOpts []otlptracegrpc.Option
+
+	tlsConf, err := conf.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Endpoint != "" {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpoint(conf.HostPort()))
+	}
+	if headers := conf.Headers; len(headers) != 0 {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithHeaders(headers))
+	}
+	if conf.Insecure() {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
+	} else if tlsConf != nil {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConf)))
+	}
+	if conf.UseCompression {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithCompressor(gzip.Name))
+	}
+	if conf.Timeout > 0 {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithTimeout(conf.Timeout))
+	}
+	if r := conf.Retry; r != nil {
+		grpcOpts = append(grpcOpts, otlptracegrpc.WithRetry(otlptracegrpc.RetryConfig{
+			Enabled:         r.Enabled,
+			InitialInterval: r.InitialInterval,
+			MaxInterval:     r.MaxInterval,
+			MaxElapsedTime:  r.MaxElapsedTime,
+		}))
+	}
+
+	return otlptracegrpc.NewClient(grpcOpts...), nil
+}
+
+func newOTLPHTTPClient(conf *config.Export) (otlptrace.Client, error) {
+	var httpOpts []otlptracehttp.Option
+
+	tlsConf, err := conf.TLS.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if conf.Endpoint != "" {
+		httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(conf.HostPort()))
+	}
+	if path := conf.URLPath("traces"); path != "" {
+		httpOpts = append(httpOpts, otlptracehttp.WithURLPath(path))
+	}
+	if headers := conf.Headers; len(headers) != 0 {
+		httpOpts = append(httpOpts, otlptracehttp.WithHeaders(headers))
+	}
+	if conf.Insecure() {
+		httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+	} else if tlsConf != nil {
+		httpOpts = append(httpOpts, otlptracehttp.WithTLSClientConfig(tlsConf))
+	}
+	if conf.UseCompression {
+		httpOpts = append(httpOpts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+	}
+	if conf.Timeout > 0 {
+		httpOpts = append(httpOpts, otlptracehttp.WithTimeout(conf.Timeout))
+	}
+	if r := conf.Retry; r != nil {
+		httpOpts = append(httpOpts, otlptracehttp.WithRetry(otlptracehttp.RetryConfig{
+			Enabled:         r.Enabled,
+			InitialInterval: r.InitialInterval,
+			MaxInterval:     r.MaxInterval,
+			MaxElapsedTime:  r.MaxElapsedTime,
+		}))
+	}
+
+	return otlptracehttp.NewClient(httpOpts...), nil
+}
+
+// newHTTPClient creates a client for the exporters that
+// only allow the TLS settings to be set using a http.Client
+func newHTTPClient(tlsConf *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConf
+	return &http.Client{Transport: transport}
+}
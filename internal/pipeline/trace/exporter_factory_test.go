@@ -1,17 +1,38 @@
 package trace_test
 
 import (
+	"bufio"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"io"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
 
 	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/retrytest"
 	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/trace"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/rotate"
+	"github.com/MovieStoreGuy/otel-go-starter/otelstartertest/collector"
 )
 
 func TestBuildingExporters(t *testing.T) {
@@ -79,3 +100,193 @@ func TestBuildingExporters(t *testing.T) {
 	_, err := factory.NewExporter(context.Background(), &config.Export{})
 	assert.ErrorIs(t, err, trace.ErrNotDefinedExporter, "Must error when invalid exporter name is provided")
 }
+
+// newCertificate creates a self signed certificate valid for localhost
+func newCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "Must be able to generate a key")
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err, "Must be able to create a certificate")
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err, "Must be able to marshal the key")
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestExportersWithMutualTLS(t *testing.T) {
+	t.Parallel()
+
+	certPEM, keyPEM := newCertificate(t)
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err, "Must be able to load the certificate")
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(certPEM))
+
+	var received int64
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) != 0 {
+			atomic.AddInt64(&received, 1)
+		}
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	s.TLS = &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	}
+	s.StartTLS()
+	t.Cleanup(s.Close)
+
+	for _, name := range []string{"zipkin", "jaeger"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			conf := &config.Export{Named: name}
+			require.NoError(t, config.WithExporterEndpoint(s.URL)(conf))
+			require.NoError(t, config.WithExporterCACertificatePEM(certPEM)(conf))
+			require.NoError(t, config.WithExporterClientCertificatePEM(certPEM, keyPEM)(conf))
+
+			exporter, err := trace.NewExporterFactory().NewExporter(context.Background(), conf)
+			require.NoError(t, err, "Must not error when configuring exporter")
+
+			before := atomic.LoadInt64(&received)
+			spans := tracetest.SpanStubs{{Name: "secure"}}.Snapshots()
+			assert.NoError(t, exporter.ExportSpans(context.Background(), spans), "Must be able to export using mutual TLS")
+			assert.Equal(t, before+1, atomic.LoadInt64(&received), "Must present the client certificate")
+			assert.NoError(t, exporter.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestOTLPExporterRetries(t *testing.T) {
+	t.Parallel()
+
+	retrytest.Run(t, func(t *testing.T, ctx context.Context, conf *config.Export) error {
+		exporter, err := trace.NewExporterFactory().NewExporter(ctx, conf)
+		require.NoError(t, err, "Must not error when configuring exporter")
+
+		err = exporter.ExportSpans(ctx, tracetest.SpanStubs{{Name: "retried"}}.Snapshots())
+		assert.NoError(t, exporter.Shutdown(ctx))
+		return err
+	})
+}
+
+func TestOTLPExportersWithCollector(t *testing.T) {
+	t.Parallel()
+
+	c := collector.New(t)
+
+	testCases := []struct {
+		name     string
+		endpoint string
+		protocol collector.Protocol
+		path     string
+	}{
+		{name: "otlpgrpc", endpoint: c.GRPCEndpoint(), protocol: collector.ProtocolGRPC},
+		{name: "otlphttp", endpoint: c.HTTPEndpoint(), protocol: collector.ProtocolHTTP, path: "/v1/traces"},
+		{name: "otlphttp", endpoint: c.HTTPEndpoint() + "/gateway/traces", protocol: collector.ProtocolHTTP, path: "/gateway/traces"},
+	}
+
+	for i, tc := range testCases {
+		conf := &config.Export{Named: tc.name}
+		require.NoError(t, config.WithExporterEndpoint(tc.endpoint)(conf))
+		require.NoError(t, config.WithExporterHeaders(map[string]string{"Service-Domain": "icecream"})(conf))
+		require.NoError(t, config.WithExporterUseCompression()(conf))
+
+		ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+		exporter, err := trace.NewExporterFactory().NewExporter(ctx, conf)
+		require.NoError(t, err, "Must not error when configuring the %s exporter", tc.name)
+
+		require.NoError(t, exporter.ExportSpans(ctx, tracetest.SpanStubs{{Name: tc.name}}.Snapshots()))
+		spans, err := c.WaitForSpans(ctx, i+1)
+		require.NoError(t, err, "Must send the spans to the collector")
+		assert.Equal(t, tc.name, spans[i].Name)
+		assert.NoError(t, exporter.Shutdown(ctx))
+		done()
+
+		req := c.Requests()[i]
+		assert.Equal(t, tc.protocol, req.Protocol, "Must use the %s endpoint", tc.protocol)
+		assert.Equal(t, collector.SignalTraces, req.Signal)
+		assert.Equal(t, "icecream", req.Headers.Get("Service-Domain"), "Must send the configured headers")
+		assert.Equal(t, "gzip", req.Compression, "Must compress the request")
+		assert.Equal(t, tc.path, req.Path, "Must send the request to the endpoint's path")
+	}
+}
+
+func TestFileExporter(t *testing.T) {
+	t.Parallel()
+
+	traceID, err := oteltrace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spans := tracetest.SpanStubs{{
+		Name:        "checkout",
+		SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{TraceID: traceID, SpanID: oteltrace.SpanID{1}}),
+	}}.Snapshots()
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	conf := &config.Export{Named: "file"}
+	require.NoError(t, config.WithExporterPath(path)(conf))
+	require.NoError(t, config.WithExporterFileSyncEachExport()(conf))
+
+	ctx := context.Background()
+	exporter, err := trace.NewExporterFactory().NewExporter(ctx, conf)
+	require.NoError(t, err, "Must not error when configuring exporter")
+	require.NoError(t, exporter.ExportSpans(ctx, spans))
+	require.NoError(t, exporter.ExportSpans(ctx, spans))
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var lines int
+	for scanner := bufio.NewScanner(f); scanner.Scan(); lines++ {
+		assert.Contains(t, scanner.Text(), `"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"`, "Must encode the IDs as hex")
+
+		var req coltracepb.ExportTraceServiceRequest
+		require.NoError(t, otlpjson.Unmarshal(scanner.Bytes(), &req), "Must write OTLP/JSON")
+		require.Len(t, req.ResourceSpans, 1)
+		assert.Equal(t, "checkout", req.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans[0].Name)
+	}
+	assert.Equal(t, 2, lines, "Must write each export on its own line")
+
+	_, err = trace.NewExporterFactory().NewExporter(ctx, &config.Export{Named: "file"})
+	assert.ErrorIs(t, err, config.ErrInvalidParam, "Must require a path for the file exporter")
+}
+
+func TestFileExporterRotation(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	conf := &config.Export{Named: "file"}
+	require.NoError(t, config.WithExporterPath(path)(conf))
+	require.NoError(t, config.WithExporterFileMaxSize(1)(conf))
+	require.NoError(t, config.WithExporterFileMaxBackups(1)(conf))
+
+	ctx := context.Background()
+	exporter, err := trace.NewExporterFactory().NewExporter(ctx, conf)
+	require.NoError(t, err, "Must not error when configuring exporter")
+	for i := 0; i < 3; i++ {
+		require.NoError(t, exporter.ExportSpans(ctx, tracetest.SpanStubs{{Name: "rotated"}}.Snapshots()))
+	}
+	require.NoError(t, exporter.Shutdown(ctx))
+
+	backups, err := rotate.Backups(path)
+	require.NoError(t, err)
+	assert.Len(t, backups, 1, "Must rotate the file once it exceeds the max size and keep a single backup")
+}
@@ -0,0 +1,49 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/rotate"
+)
+
+// fileClient writes each upload as a line of OTLP/JSON,
+// allowing the OTLP exporter to be used without a collector
+type fileClient struct {
+	path string
+	conf config.File
+	f    *rotate.File
+}
+
+var _ otlptrace.Client = (*fileClient)(nil)
+
+func newFileClient(conf *config.Export) (otlptrace.Client, error) {
+	if conf.Path == "" {
+		return nil, fmt.Errorf("file exporter requires a path: %w", config.ErrInvalidParam)
+	}
+	return &fileClient{path: conf.Path, conf: conf.File}, nil
+}
+
+func (fc *fileClient) Start(context.Context) (err error) {
+	fc.f, err = rotate.Open(fc.path, fc.conf)
+	return err
+}
+
+func (fc *fileClient) Stop(context.Context) error {
+	return fc.f.Close()
+}
+
+func (fc *fileClient) UploadTraces(_ context.Context, spans []*tracepb.ResourceSpans) error {
+	b, err := otlpjson.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: spans})
+	if err != nil {
+		return err
+	}
+	_, err = fc.f.Write(append(b, '\n'))
+	return err
+}
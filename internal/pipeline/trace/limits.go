@@ -0,0 +1,213 @@
+package trace
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+)
+
+// NewSpanLimits converts the configured limits into the SDK span limits,
+// unset limits are left as zero so that the SDK uses its defaults
+func NewSpanLimits(l config.Limits) sdktrace.SpanLimits {
+	return sdktrace.SpanLimits{
+		AttributeCountLimit:         l.AttributeCount,
+		EventCountLimit:             l.EventCount,
+		LinkCountLimit:              l.LinkCount,
+		AttributePerEventCountLimit: l.AttributePerEventCount,
+		AttributePerLinkCountLimit:  l.AttributePerLinkCount,
+	}
+}
+
+// truncatingProcessor shortens string attribute values since the SDK span limits
+// do not restrict the length of values. The attributes a span starts with are
+// truncated on the span itself so oversized values are not held while the span
+// is recording, and ended spans are passed on with every value truncated so that
+// the queues of the wrapped processor only hold the truncated values.
+type truncatingProcessor struct {
+	sdktrace.SpanProcessor
+	limit int
+}
+
+var _ sdktrace.SpanProcessor = (*truncatingProcessor)(nil)
+
+// NewTruncatingProcessor wraps the processor so that string attribute values on the span,
+// its events and its links are truncated to limit characters.
+// The processor is returned unchanged when limit is not positive.
+func NewTruncatingProcessor(processor sdktrace.SpanProcessor, limit int) sdktrace.SpanProcessor {
+	if limit <= 0 {
+		return processor
+	}
+	return &truncatingProcessor{SpanProcessor: processor, limit: limit}
+}
+
+func (tp *truncatingProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	if attrs, ok := tp.truncate(s.Attributes()); ok {
+		// Setting an existing key replaces its value
+		s.SetAttributes(attrs...)
+	}
+	tp.SpanProcessor.OnStart(parent, s)
+}
+
+func (tp *truncatingProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	var (
+		attrs, truncated = tp.truncate(s.Attributes())
+		events           = s.Events()
+		links            = s.Links()
+
+		// The events and links are shared with the other span processors,
+		// so each is copied before it is first written
+		eventsCopied, linksCopied bool
+	)
+	for i := range events {
+		if a, ok := tp.truncate(events[i].Attributes); ok {
+			if !eventsCopied {
+				events, eventsCopied = append([]sdktrace.Event(nil), events...), true
+			}
+			events[i].Attributes, truncated = a, true
+		}
+	}
+	for i := range links {
+		if a, ok := tp.truncate(links[i].Attributes); ok {
+			if !linksCopied {
+				links, linksCopied = append([]sdktrace.Link(nil), links...), true
+			}
+			links[i].Attributes, truncated = a, true
+		}
+	}
+	if !truncated {
+		tp.SpanProcessor.OnEnd(s)
+		return
+	}
+	tp.SpanProcessor.OnEnd(newTruncatedSpan(s, attrs, events, links))
+}
+
+// truncate returns the attributes with the string values shortened to the limit
+// and whether any value was truncated, the attributes are copied when truncated
+// since the slice is shared with the other span processors
+func (tp *truncatingProcessor) truncate(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	var out []attribute.KeyValue
+	for i, kv := range attrs {
+		truncated, ok := tp.truncateValue(kv)
+		if ok && out == nil {
+			out = append(make([]attribute.KeyValue, 0, len(attrs)), attrs[:i]...)
+		}
+		if out != nil {
+			out = append(out, truncated)
+		}
+	}
+	if out == nil {
+		return attrs, false
+	}
+	return out, true
+}
+
+func (tp *truncatingProcessor) truncateValue(kv attribute.KeyValue) (attribute.KeyValue, bool) {
+	switch kv.Value.Type() {
+	case attribute.STRING:
+		if v := kv.Value.AsString(); len(v) > tp.limit {
+			if tv := truncateString(v, tp.limit); tv != v {
+				return kv.Key.String(tv), true
+			}
+		}
+	case attribute.STRINGSLICE:
+		var values []string
+		for i, v := range kv.Value.AsStringSlice() {
+			if tv := truncateString(v, tp.limit); tv != v {
+				if values == nil {
+					values = append([]string(nil), kv.Value.AsStringSlice()...)
+				}
+				values[i] = tv
+			}
+		}
+		if values != nil {
+			return kv.Key.StringSlice(values), true
+		}
+	}
+	return kv, false
+}
+
+// truncatedSpan is a copy of an ended span holding the truncated attributes,
+// it does not reference the span it was copied from so that the original
+// values are released once the span has ended
+type truncatedSpan struct {
+	// Embedded to implement the private method of the interface, it is always nil
+	sdktrace.ReadOnlySpan
+
+	name                   string
+	spanContext            trace.SpanContext
+	parent                 trace.SpanContext
+	spanKind               trace.SpanKind
+	startTime, endTime     time.Time
+	attributes             []attribute.KeyValue
+	events                 []sdktrace.Event
+	links                  []sdktrace.Link
+	status                 sdktrace.Status
+	instrumentationLibrary instrumentation.Library
+	resource               *resource.Resource
+	droppedAttributes      int
+	droppedLinks           int
+	droppedEvents          int
+	childSpanCount         int
+}
+
+func newTruncatedSpan(s sdktrace.ReadOnlySpan, attrs []attribute.KeyValue, events []sdktrace.Event, links []sdktrace.Link) *truncatedSpan {
+	return &truncatedSpan{
+		name:                   s.Name(),
+		spanContext:            s.SpanContext(),
+		parent:                 s.Parent(),
+		spanKind:               s.SpanKind(),
+		startTime:              s.StartTime(),
+		endTime:                s.EndTime(),
+		attributes:             attrs,
+		events:                 events,
+		links:                  links,
+		status:                 s.Status(),
+		instrumentationLibrary: s.InstrumentationLibrary(),
+		resource:               s.Resource(),
+		droppedAttributes:      s.DroppedAttributes(),
+		droppedLinks:           s.DroppedLinks(),
+		droppedEvents:          s.DroppedEvents(),
+		childSpanCount:         s.ChildSpanCount(),
+	}
+}
+
+func (ts *truncatedSpan) Name() string                     { return ts.name }
+func (ts *truncatedSpan) SpanContext() trace.SpanContext   { return ts.spanContext }
+func (ts *truncatedSpan) Parent() trace.SpanContext        { return ts.parent }
+func (ts *truncatedSpan) SpanKind() trace.SpanKind         { return ts.spanKind }
+func (ts *truncatedSpan) StartTime() time.Time             { return ts.startTime }
+func (ts *truncatedSpan) EndTime() time.Time               { return ts.endTime }
+func (ts *truncatedSpan) Attributes() []attribute.KeyValue { return ts.attributes }
+func (ts *truncatedSpan) Links() []sdktrace.Link           { return ts.links }
+func (ts *truncatedSpan) Events() []sdktrace.Event         { return ts.events }
+func (ts *truncatedSpan) Status() sdktrace.Status          { return ts.status }
+func (ts *truncatedSpan) InstrumentationLibrary() instrumentation.Library {
+	return ts.instrumentationLibrary
+}
+func (ts *truncatedSpan) Resource() *resource.Resource { return ts.resource }
+func (ts *truncatedSpan) DroppedAttributes() int       { return ts.droppedAttributes }
+func (ts *truncatedSpan) DroppedLinks() int            { return ts.droppedLinks }
+func (ts *truncatedSpan) DroppedEvents() int           { return ts.droppedEvents }
+func (ts *truncatedSpan) ChildSpanCount() int          { return ts.childSpanCount }
+
+// truncateString shortens s to limit characters without splitting a multibyte character
+func truncateString(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	count := 0
+	for i := range s {
+		if count == limit {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
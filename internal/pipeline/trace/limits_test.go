@@ -0,0 +1,163 @@
+package trace_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/trace"
+)
+
+func TestSpanLimits(t *testing.T) {
+	t.Parallel()
+
+	limits := config.Limits{
+		AttributeCount:       3,
+		AttributeValueLength: 4,
+		EventCount:           1,
+		LinkCount:            1,
+	}
+
+	inmemory := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanLimits(trace.NewSpanLimits(limits)),
+		sdktrace.WithSpanProcessor(trace.NewTruncatingProcessor(sdktrace.NewSimpleSpanProcessor(inmemory), limits.AttributeValueLength)),
+	)
+
+	ctx := context.Background()
+	linked := oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+		TraceID: oteltrace.TraceID{0x01},
+		SpanID:  oteltrace.SpanID{0x01},
+	})
+	_, span := tp.Tracer("test").Start(ctx, "limited",
+		oteltrace.WithLinks(
+			oteltrace.Link{SpanContext: linked},
+			oteltrace.Link{SpanContext: linked, Attributes: []attribute.KeyValue{attribute.String("link", "abcdefgh")}},
+		),
+	)
+	span.SetAttributes(
+		attribute.String("dropped", "abc"),
+		attribute.String("short", "abc"),
+		attribute.String("long", "ééééééé"),
+		attribute.StringSlice("many", []string{"abcdef", "ab"}),
+	)
+	span.AddEvent("dropped")
+	span.AddEvent("kept", oteltrace.WithAttributes(attribute.String("event", "abcdefgh")))
+	span.End()
+	require.NoError(t, tp.ForceFlush(ctx))
+
+	spans := inmemory.GetSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+
+	assert.Equal(t, 1, s.DroppedAttributes, "Must drop the attributes over the count limit")
+	require.Len(t, s.Events, 1, "Must drop the events over the count limit")
+	require.Len(t, s.Links, 1, "Must drop the links over the count limit")
+
+	attrs := attribute.NewSet(s.Attributes...)
+	if v, ok := attrs.Value("short"); assert.True(t, ok) {
+		assert.Equal(t, "abc", v.AsString(), "Must not modify values within the limit")
+	}
+	if v, ok := attrs.Value("many"); assert.True(t, ok) {
+		assert.Equal(t, []string{"abcd", "ab"}, v.AsStringSlice(), "Must truncate each value within a slice")
+	}
+	if v, ok := attrs.Value("long"); assert.True(t, ok) {
+		assert.Equal(t, "éééé", v.AsString(), "Must truncate on character boundaries")
+	}
+	assert.Equal(t, []attribute.KeyValue{attribute.String("event", "abcd")}, s.Events[0].Attributes)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("link", "abcd")}, s.Links[0].Attributes)
+}
+
+func TestTruncatingProcessorStart(t *testing.T) {
+	t.Parallel()
+
+	var (
+		inmemory = tracetest.NewInMemoryExporter()
+		started  = &startRecorder{}
+	)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(trace.NewTruncatingProcessor(started, 4)),
+		sdktrace.WithSyncer(inmemory),
+	)
+
+	_, span := tp.Tracer("test").Start(context.Background(), "started",
+		oteltrace.WithAttributes(attribute.String("long", "abcdefgh"), attribute.Int("count", 1)),
+	)
+	assert.ElementsMatch(t, []attribute.KeyValue{attribute.String("long", "abcd"), attribute.Int("count", 1)}, started.attrs,
+		"Must truncate the attributes the span starts with before the wrapped processor")
+	span.End()
+
+	spans := inmemory.GetSpans()
+	require.Len(t, spans, 1)
+	assert.ElementsMatch(t, []attribute.KeyValue{attribute.String("long", "abcd"), attribute.Int("count", 1)}, spans[0].Attributes,
+		"Must truncate the recording span so other processors do not see the original value")
+}
+
+// startRecorder keeps the attributes of the last span started
+type startRecorder struct {
+	attrs []attribute.KeyValue
+}
+
+func (sr *startRecorder) OnStart(_ context.Context, s sdktrace.ReadWriteSpan) {
+	sr.attrs = s.Attributes()
+}
+func (sr *startRecorder) OnEnd(sdktrace.ReadOnlySpan)      {}
+func (sr *startRecorder) Shutdown(context.Context) error   { return nil }
+func (sr *startRecorder) ForceFlush(context.Context) error { return nil }
+
+func TestTruncatingProcessorDisabled(t *testing.T) {
+	t.Parallel()
+
+	var (
+		inmemory  = tracetest.NewInMemoryExporter()
+		processor = sdktrace.NewSimpleSpanProcessor(inmemory)
+	)
+	assert.Same(t, processor, trace.NewTruncatingProcessor(processor, 0), "Must not wrap the processor without a limit")
+
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(trace.NewTruncatingProcessor(processor, 0)))
+	_, span := tp.Tracer("test").Start(context.Background(), "unlimited")
+	span.SetAttributes(attribute.String("long", strings.Repeat("a", 1024)))
+	span.End()
+
+	spans := inmemory.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, strings.Repeat("a", 1024), spans[0].Attributes[0].Value.AsString())
+}
+
+func TestTruncatingProcessorCopies(t *testing.T) {
+	t.Parallel()
+
+	inmemory := tracetest.NewInMemoryExporter()
+	processor := trace.NewTruncatingProcessor(sdktrace.NewSimpleSpanProcessor(inmemory), 4)
+
+	long := []attribute.KeyValue{attribute.String("long", "abcdefgh")}
+	span := tracetest.SpanStub{
+		Name: "shared",
+		SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID:    oteltrace.TraceID{0x01},
+			SpanID:     oteltrace.SpanID{0x01},
+			TraceFlags: oteltrace.FlagsSampled,
+		}),
+		Attributes: long,
+		Events:     []sdktrace.Event{{Name: "event", Attributes: long}},
+		Links:      []sdktrace.Link{{Attributes: long}},
+	}.Snapshot()
+	processor.OnEnd(span)
+
+	spans := inmemory.GetSpans()
+	require.Len(t, spans, 1)
+	truncated := []attribute.KeyValue{attribute.String("long", "abcd")}
+	assert.Equal(t, truncated, spans[0].Events[0].Attributes, "Must truncate the event attributes")
+	assert.Equal(t, truncated, spans[0].Links[0].Attributes, "Must truncate the link attributes")
+
+	assert.Equal(t, long, span.Events()[0].Attributes, "Must not modify the events shared with other processors")
+	assert.Equal(t, long, span.Links()[0].Attributes, "Must not modify the links shared with other processors")
+}
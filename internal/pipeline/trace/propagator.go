@@ -13,9 +13,11 @@ import (
 func NewPropagators(use []string) (propagation.TextMapPropagator, error) {
 	propergatorMap := map[string]propagation.TextMapPropagator{
 		"b3":           b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
+		"b3multi":      b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)),
 		"baggage":      propagation.Baggage{},
 		"tracecontext": propagation.TraceContext{},
 		"ottrace":      ot.OT{},
+		"none":         propagation.NewCompositeTextMapPropagator(),
 	}
 
 	var props []propagation.TextMapPropagator
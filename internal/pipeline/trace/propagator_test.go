@@ -16,7 +16,8 @@ func TestLoadingPropagators(t *testing.T) {
 		scenario string
 		use      []string
 	}{
-		{scenario: "Using all propagators", use: []string{"b3", "baggage", "tracecontext", "ottrace"}},
+		{scenario: "Using all propagators", use: []string{"b3", "b3multi", "baggage", "tracecontext", "ottrace"}},
+		{scenario: "Using no propagation", use: []string{"none"}},
 		{scenario: "Using one propagator", use: []string{"b3"}},
 	}
 
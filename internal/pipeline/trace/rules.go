@@ -0,0 +1,122 @@
+package trace
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+)
+
+type rule struct {
+	name     string
+	kind     trace.SpanKind
+	equals   map[attribute.Key]string
+	patterns map[attribute.Key]*regexp.Regexp
+	sampler  sdktrace.Sampler
+}
+
+// ruleBased samples root spans using the first matching rule,
+// spans that do not match any rules or have a parent use the fallback sampler
+type ruleBased struct {
+	rules    []rule
+	fallback sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = (*ruleBased)(nil)
+
+// NewRuleBased returns a sampler that evaluates the rules in order for root spans,
+// using the sampler of the first matching rule or the fallback sampler if none match.
+// Spans with a local or remote parent always use the fallback sampler, so a parent based
+// fallback keeps the decision of the root span for the rest of the trace.
+func NewRuleBased(rules []config.SamplingRule, fallback sdktrace.Sampler) (sdktrace.Sampler, error) {
+	if fallback == nil {
+		return nil, fmt.Errorf("fallback sampler is nil: %w", config.ErrNilParamProvided)
+	}
+	rb := &ruleBased{fallback: fallback}
+	for i, r := range rules {
+		compiled, err := newRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("sampling rule %d: %w", i, err)
+		}
+		rb.rules = append(rb.rules, compiled)
+	}
+	return rb, nil
+}
+
+func newRule(r config.SamplingRule) (rule, error) {
+	if err := r.Validate(); err != nil {
+		return rule{}, err
+	}
+	compiled := rule{
+		name:     r.SpanName,
+		kind:     r.Kind(),
+		equals:   make(map[attribute.Key]string, len(r.Attributes)),
+		patterns: make(map[attribute.Key]*regexp.Regexp, len(r.AttributePatterns)),
+	}
+	for k, v := range r.Attributes {
+		compiled.equals[attribute.Key(k)] = v
+	}
+	for k, pattern := range r.AttributePatterns {
+		// The patterns are checked by Validate
+		compiled.patterns[attribute.Key(k)] = regexp.MustCompile(pattern)
+	}
+	sampler, err := newNamedSampler(r.Sampler)
+	if err != nil {
+		return rule{}, err
+	}
+	compiled.sampler = sampler
+	return compiled, nil
+}
+
+func (r rule) matches(p sdktrace.SamplingParameters) bool {
+	if r.name != "" {
+		if ok, _ := path.Match(r.name, p.Name); !ok {
+			return false
+		}
+	}
+	if r.kind != trace.SpanKindUnspecified && r.kind != p.Kind {
+		return false
+	}
+	if len(r.equals) == 0 && len(r.patterns) == 0 {
+		return true
+	}
+
+	values := make(map[attribute.Key]string, len(p.Attributes))
+	for _, kv := range p.Attributes {
+		values[kv.Key] = kv.Value.Emit()
+	}
+	for k, expect := range r.equals {
+		if v, exist := values[k]; !exist || v != expect {
+			return false
+		}
+	}
+	for k, re := range r.patterns {
+		if v, exist := values[k]; !exist || !re.MatchString(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (rb *ruleBased) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	// Rules only decide for root spans so that a trace is not broken by a rule
+	// sampling part of it, spans with a parent use the fallback sampler
+	if trace.SpanContextFromContext(p.ParentContext).IsValid() {
+		return rb.fallback.ShouldSample(p)
+	}
+	for _, r := range rb.rules {
+		if r.matches(p) {
+			return r.sampler.ShouldSample(p)
+		}
+	}
+	return rb.fallback.ShouldSample(p)
+}
+
+func (rb *ruleBased) Description() string {
+	return fmt.Sprintf("RuleBased{rules:%d,fallback:%s}", len(rb.rules), rb.fallback.Description())
+}
@@ -0,0 +1,162 @@
+package trace_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/trace"
+)
+
+func TestRuleBasedSampler(t *testing.T) {
+	t.Parallel()
+
+	sampler, err := trace.NewSampler(&config.Tracing{
+		Sampler: config.Sampler{Name: config.SamplerAlwaysOn},
+		SamplingRules: []config.SamplingRule{
+			{
+				SpanKind:   "server",
+				Attributes: map[string]string{"http.target": "/healthz"},
+				Sampler:    config.Sampler{Name: config.SamplerAlwaysOff},
+			},
+			{
+				SpanName:          "scrape *",
+				AttributePatterns: map[string]string{"http.target": "^/metrics"},
+				Sampler:           config.Sampler{Name: config.SamplerAlwaysOff},
+			},
+			{
+				SpanName: "batch.*",
+				Sampler:  config.Sampler{Name: config.SamplerRateLimited, Arg: 1},
+			},
+		},
+	})
+	require.NoError(t, err, "Must not error with valid rules")
+	assert.Contains(t, sampler.Description(), "RuleBased{rules:3")
+
+	testCases := []struct {
+		scenario string
+		name     string
+		kind     oteltrace.SpanKind
+		attrs    []attribute.KeyValue
+		decision sdktrace.SamplingDecision
+	}{
+		{
+			scenario: "Health check matches attribute equality",
+			name:     "GET",
+			kind:     oteltrace.SpanKindServer,
+			attrs:    []attribute.KeyValue{attribute.String("http.target", "/healthz")},
+			decision: sdktrace.Drop,
+		},
+		{
+			scenario: "Health check from a client does not match the kind",
+			name:     "GET",
+			kind:     oteltrace.SpanKindClient,
+			attrs:    []attribute.KeyValue{attribute.String("http.target", "/healthz")},
+			decision: sdktrace.RecordAndSample,
+		},
+		{
+			scenario: "Scrape matches the name and pattern",
+			name:     "scrape metrics",
+			attrs:    []attribute.KeyValue{attribute.String("http.target", "/metrics/cadvisor")},
+			decision: sdktrace.Drop,
+		},
+		{
+			scenario: "Scrape missing the attribute uses the fallback",
+			name:     "scrape metrics",
+			decision: sdktrace.RecordAndSample,
+		},
+		{
+			scenario: "Unmatched span uses the fallback",
+			name:     "checkout",
+			kind:     oteltrace.SpanKindServer,
+			attrs:    []attribute.KeyValue{attribute.String("http.target", "/cart")},
+			decision: sdktrace.RecordAndSample,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: context.Background(),
+				TraceID:       oteltrace.TraceID{1},
+				Name:          tc.name,
+				Kind:          tc.kind,
+				Attributes:    tc.attrs,
+			})
+			assert.Equal(t, tc.decision, result.Decision)
+		})
+	}
+
+	params := sdktrace.SamplingParameters{ParentContext: context.Background(), Name: "batch.job"}
+	assert.Equal(t, sdktrace.RecordAndSample, sampler.ShouldSample(params).Decision, "Must sample within the rule's rate")
+	assert.Equal(t, sdktrace.Drop, sampler.ShouldSample(params).Decision, "Must drop spans exceeding the rule's rate")
+}
+
+func TestRuleBasedSamplerParent(t *testing.T) {
+	t.Parallel()
+
+	sampler, err := trace.NewSampler(&config.Tracing{
+		Sampler: config.Sampler{Name: config.SamplerParentBasedAlwaysOff},
+		SamplingRules: []config.SamplingRule{
+			{SpanName: "healthz", Sampler: config.Sampler{Name: config.SamplerAlwaysOff}},
+			{SpanName: "debug", Sampler: config.Sampler{Name: config.SamplerAlwaysOn}},
+		},
+	})
+	require.NoError(t, err)
+
+	parent := func(flags oteltrace.TraceFlags) context.Context {
+		return oteltrace.ContextWithRemoteSpanContext(context.Background(), oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID:    oteltrace.TraceID{1},
+			SpanID:     oteltrace.SpanID{1},
+			TraceFlags: flags,
+			Remote:     true,
+		}))
+	}
+
+	testCases := []struct {
+		scenario string
+		ctx      context.Context
+		name     string
+		decision sdktrace.SamplingDecision
+	}{
+		{scenario: "Root span uses the rule", ctx: context.Background(), name: "debug", decision: sdktrace.RecordAndSample},
+		{scenario: "Child of a sampled parent is not dropped by a rule", ctx: parent(oteltrace.FlagsSampled), name: "healthz", decision: sdktrace.RecordAndSample},
+		{scenario: "Child of an unsampled parent is not sampled by a rule", ctx: parent(0), name: "debug", decision: sdktrace.Drop},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: tc.ctx,
+				TraceID:       oteltrace.TraceID{1},
+				Name:          tc.name,
+			})
+			assert.Equal(t, tc.decision, result.Decision)
+		})
+	}
+}
+
+func TestInvalidSamplingRules(t *testing.T) {
+	t.Parallel()
+
+	for _, r := range []config.SamplingRule{
+		{SpanName: "[", Sampler: config.Sampler{Name: config.SamplerAlwaysOn}},
+		{SpanKind: "sideways", Sampler: config.Sampler{Name: config.SamplerAlwaysOn}},
+		{AttributePatterns: map[string]string{"http.target": "("}, Sampler: config.Sampler{Name: config.SamplerAlwaysOn}},
+		{Sampler: config.Sampler{Name: "sometimes"}},
+	} {
+		_, err := trace.NewRuleBased([]config.SamplingRule{r}, sdktrace.AlwaysSample())
+		assert.ErrorIs(t, err, config.ErrInvalidParam, "Must error with invalid rule %+v", r)
+	}
+
+	_, err := trace.NewRuleBased(nil, nil)
+	assert.ErrorIs(t, err, config.ErrNilParamProvided)
+}
@@ -0,0 +1,118 @@
+package trace
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+)
+
+// NewSampler creates the sampler defined by the tracing configuration,
+// any sampling rules are evaluated before the configured sampler
+func NewSampler(conf *config.Tracing) (sdktrace.Sampler, error) {
+	s := conf.Sampler
+	if s.Name == "" {
+		s.Name = config.SamplerParentBasedAlwaysOn
+		if conf.Sample {
+			s.Name = config.SamplerAlwaysOn
+		}
+	}
+
+	fallback, err := newNamedSampler(s)
+	if err != nil || len(conf.SamplingRules) == 0 {
+		return fallback, err
+	}
+	return NewRuleBased(conf.SamplingRules, fallback)
+}
+
+func newNamedSampler(s config.Sampler) (sdktrace.Sampler, error) {
+	switch s.Name {
+	case config.SamplerAlwaysOn:
+		return sdktrace.AlwaysSample(), nil
+	case config.SamplerAlwaysOff:
+		return sdktrace.NeverSample(), nil
+	case config.SamplerTraceIDRatio:
+		return sdktrace.TraceIDRatioBased(s.Arg), nil
+	case config.SamplerRateLimited:
+		return NewRateLimited(s.Arg)
+	case config.SamplerParentBasedAlwaysOn:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample()), nil
+	case config.SamplerParentBasedAlwaysOff:
+		return sdktrace.ParentBased(sdktrace.NeverSample()), nil
+	case config.SamplerParentBasedTraceIDRatio:
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(s.Arg)), nil
+	case config.SamplerParentBasedRateLimited:
+		root, err := NewRateLimited(s.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return sdktrace.ParentBased(root), nil
+	}
+	return nil, fmt.Errorf("unsupported sampler %q: %w", s.Name, config.ErrInvalidParam)
+}
+
+// rateLimited is a token bucket sampler that samples
+// up to the configured number of traces per second
+type rateLimited struct {
+	mu sync.Mutex
+
+	rate    float64
+	balance float64
+	last    time.Time
+	now     func() time.Time
+}
+
+var _ sdktrace.Sampler = (*rateLimited)(nil)
+
+// NewRateLimited returns a sampler that samples at most perSecond traces each second,
+// allowing for bursts of up to one second's worth of traces.
+func NewRateLimited(perSecond float64) (sdktrace.Sampler, error) {
+	return newRateLimited(perSecond, time.Now)
+}
+
+func newRateLimited(perSecond float64, now func() time.Time) (*rateLimited, error) {
+	if perSecond <= 0 || math.IsInf(perSecond, 0) || math.IsNaN(perSecond) {
+		return nil, fmt.Errorf("rate %v must be greater than 0: %w", perSecond, config.ErrInvalidParam)
+	}
+	return &rateLimited{
+		rate:    perSecond,
+		balance: math.Max(perSecond, 1),
+		last:    now(),
+		now:     now,
+	}, nil
+}
+
+func (rl *rateLimited) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := sdktrace.SamplingResult{
+		Decision:   sdktrace.Drop,
+		Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+	}
+	if rl.take() {
+		result.Decision = sdktrace.RecordAndSample
+	}
+	return result
+}
+
+func (rl *rateLimited) take() bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.balance = math.Min(rl.balance+now.Sub(rl.last).Seconds()*rl.rate, math.Max(rl.rate, 1))
+	rl.last = now
+
+	if rl.balance < 1 {
+		return false
+	}
+	rl.balance--
+	return true
+}
+
+func (rl *rateLimited) Description() string {
+	return fmt.Sprintf("RateLimited{%g}", rl.rate)
+}
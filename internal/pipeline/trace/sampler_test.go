@@ -0,0 +1,86 @@
+package trace_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/trace"
+)
+
+func TestNewSampler(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		scenario    string
+		conf        config.Tracing
+		description string
+	}{
+		{scenario: "Default sampler", conf: config.Tracing{}, description: "ParentBased{root:AlwaysOnSampler"},
+		{scenario: "Deprecated sample flag", conf: config.Tracing{Sample: true}, description: "AlwaysOnSampler"},
+		{scenario: "Always off", conf: config.Tracing{Sampler: config.Sampler{Name: config.SamplerAlwaysOff}}, description: "AlwaysOffSampler"},
+		{
+			scenario:    "Trace ID ratio",
+			conf:        config.Tracing{Sampler: config.Sampler{Name: config.SamplerTraceIDRatio, Arg: 0.5}},
+			description: "TraceIDRatioBased{0.5}",
+		},
+		{
+			scenario:    "Rate limited",
+			conf:        config.Tracing{Sampler: config.Sampler{Name: config.SamplerRateLimited, Arg: 10}},
+			description: "RateLimited{10}",
+		},
+		{
+			scenario:    "Parent based ratio",
+			conf:        config.Tracing{Sampler: config.Sampler{Name: config.SamplerParentBasedTraceIDRatio, Arg: 0.25}},
+			description: "ParentBased{root:TraceIDRatioBased{0.25}",
+		},
+		{
+			scenario:    "Parent based rate limited",
+			conf:        config.Tracing{Sampler: config.Sampler{Name: config.SamplerParentBasedRateLimited, Arg: 5}},
+			description: "ParentBased{root:RateLimited{5}",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.scenario, func(t *testing.T) {
+			t.Parallel()
+
+			sampler, err := trace.NewSampler(&tc.conf)
+			require.NoError(t, err, "Must not error with a valid sampler")
+			assert.Contains(t, sampler.Description(), tc.description)
+		})
+	}
+
+	_, err := trace.NewSampler(&config.Tracing{Sampler: config.Sampler{Name: "sometimes"}})
+	assert.ErrorIs(t, err, config.ErrInvalidParam, "Must error with an unknown sampler")
+
+	_, err = trace.NewSampler(&config.Tracing{Sampler: config.Sampler{Name: config.SamplerRateLimited}})
+	assert.ErrorIs(t, err, config.ErrInvalidParam, "Must error with an invalid rate")
+}
+
+func TestRateLimitedSampler(t *testing.T) {
+	t.Parallel()
+
+	sampler, err := trace.NewRateLimited(3)
+	require.NoError(t, err)
+
+	params := sdktrace.SamplingParameters{
+		ParentContext: context.Background(),
+		TraceID:       oteltrace.TraceID{1},
+		Name:          "test",
+	}
+
+	sampled := 0
+	for i := 0; i < 10; i++ {
+		if sampler.ShouldSample(params).Decision == sdktrace.RecordAndSample {
+			sampled++
+		}
+	}
+	assert.Equal(t, 3, sampled, "Must only sample up to the configured rate")
+}
@@ -0,0 +1,331 @@
+// Package replay sends the OTLP/JSON lines written by the file exporters
+// using the OTLP clients, allowing telemetry captured without access
+// to a collector to be uploaded later.
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
+)
+
+// stopTimeout limits how long stopping the clients can take,
+// they are stopped with a new context since ctx is already done when replaying is cancelled
+const stopTimeout = 30 * time.Second
+
+var (
+	// ErrUnsupportedLine is returned for lines that do not contain spans or metrics
+	ErrUnsupportedLine = errors.New("line does not contain spans or metrics")
+	// ErrNoClient is returned when a line's signal has no client to send it
+	ErrNoClient = errors.New("no client configured")
+)
+
+// Options define how the files are replayed
+type Options struct {
+	// Traces and Metrics send the lines containing spans and metrics,
+	// they are started and stopped by Replay and are not used for a dry run
+	Traces  otlptrace.Client
+	Metrics otlpmetric.Client
+	// Rate limits the number of lines sent each second, zero is unlimited
+	Rate float64
+	// Checkpoint is the file recording the position reached within each file,
+	// replaying the same files again resumes from the recorded positions
+	Checkpoint string
+	// DryRun validates every line without sending them or updating the checkpoint
+	DryRun bool
+}
+
+// Summary counts what was replayed, or would have been for a dry run
+type Summary struct {
+	Lines   int
+	Spans   int
+	Metrics int
+}
+
+// position is where replaying a file resumes from, Head identifies the file
+// the position was recorded for so that a file rotated and replaced at the same
+// path is replayed from the start rather than from an offset into another file
+type position struct {
+	Offset int64  `json:"offset"`
+	Line   int    `json:"line"`
+	Head   string `json:"head,omitempty"`
+}
+
+// Replay sends each line of the files in order, a line is only recorded in the checkpoint
+// once it has been sent so replaying stops at the first line that fails.
+// A dry run instead reports every invalid line.
+func Replay(ctx context.Context, opts Options, paths ...string) (summary Summary, err error) {
+	positions, err := readCheckpoint(opts.Checkpoint)
+	if err != nil {
+		return summary, err
+	}
+
+	r := &replayer{opts: opts, positions: positions}
+	if !opts.DryRun {
+		stop, err := r.start(ctx)
+		if err != nil {
+			return summary, err
+		}
+		defer func() {
+			err = multierr.Append(err, stop())
+		}()
+	}
+
+	for _, path := range paths {
+		if err := r.replayFile(ctx, path); err != nil {
+			return r.summary, err
+		}
+	}
+	return r.summary, r.invalid
+}
+
+type replayer struct {
+	opts      Options
+	positions map[string]position
+	summary   Summary
+	// invalid collects the invalid lines found during a dry run
+	invalid error
+	next    time.Time
+}
+
+// start starts the configured clients, returning a function to stop them
+func (r *replayer) start(ctx context.Context) (func() error, error) {
+	var stops []func(context.Context) error
+	stop := func() (err error) {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		for _, fn := range stops {
+			err = multierr.Append(err, fn(ctx))
+		}
+		return err
+	}
+	if c := r.opts.Traces; c != nil {
+		if err := c.Start(ctx); err != nil {
+			return nil, fmt.Errorf("start traces client: %w", err)
+		}
+		stops = append(stops, c.Stop)
+	}
+	if c := r.opts.Metrics; c != nil {
+		if err := c.Start(ctx); err != nil {
+			return nil, multierr.Append(fmt.Errorf("start metrics client: %w", err), stop())
+		}
+		stops = append(stops, c.Stop)
+	}
+	return stop, nil
+}
+
+func (r *replayer) replayFile(ctx context.Context, path string) error {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	pos, err := resume(f, r.positions[key])
+	if err != nil {
+		return fmt.Errorf("%s: resume from checkpoint: %w", path, err)
+	}
+
+	br := bufio.NewReader(f)
+	for {
+		line, rerr := br.ReadBytes('\n')
+		if rerr != nil && !errors.Is(rerr, io.EOF) {
+			return fmt.Errorf("%s: %w", path, rerr)
+		}
+		if len(line) == 0 {
+			return nil
+		}
+		if pos.Line == 0 {
+			pos.Head = head(line)
+		}
+		pos.Offset += int64(len(line))
+		pos.Line++
+
+		if err := r.replayLine(ctx, bytes.TrimSpace(line)); err != nil {
+			err = fmt.Errorf("%s:%d: %w", path, pos.Line, err)
+			if !r.opts.DryRun {
+				return err
+			}
+			r.invalid = multierr.Append(r.invalid, err)
+		}
+		if !r.opts.DryRun {
+			r.positions[key] = pos
+			if err := writeCheckpoint(r.opts.Checkpoint, r.positions); err != nil {
+				return err
+			}
+		}
+		if rerr != nil {
+			return nil
+		}
+	}
+}
+
+// resume seeks the file to the recorded position, starting from the beginning
+// when the file is smaller than the position or its first line differs since
+// the position was then recorded for a file that has since been rotated
+func resume(f *os.File, pos position) (position, error) {
+	if pos.Offset == 0 {
+		return position{}, nil
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return position{}, err
+	}
+	if info.Size() < pos.Offset {
+		return position{}, nil
+	}
+	first, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return position{}, err
+	}
+	if head(first) != pos.Head {
+		_, err = f.Seek(0, io.SeekStart)
+		return position{}, err
+	}
+	_, err = f.Seek(pos.Offset, io.SeekStart)
+	return pos, err
+}
+
+// head identifies a file by a hash of its first line
+func head(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *replayer) replayLine(ctx context.Context, line []byte) error {
+	if len(line) == 0 {
+		return nil
+	}
+
+	var probe struct {
+		ResourceSpans   json.RawMessage `json:"resourceSpans"`
+		ResourceMetrics json.RawMessage `json:"resourceMetrics"`
+	}
+	if err := json.Unmarshal(line, &probe); err != nil {
+		return err
+	}
+
+	switch {
+	case probe.ResourceSpans != nil:
+		var req coltracepb.ExportTraceServiceRequest
+		if err := otlpjson.Unmarshal(line, &req); err != nil {
+			return err
+		}
+		if !r.opts.DryRun {
+			if r.opts.Traces == nil {
+				return fmt.Errorf("spans: %w", ErrNoClient)
+			}
+			if err := r.wait(ctx); err != nil {
+				return err
+			}
+			if err := r.opts.Traces.UploadTraces(ctx, req.ResourceSpans); err != nil {
+				return err
+			}
+		}
+		for _, rs := range req.ResourceSpans {
+			for _, ils := range rs.InstrumentationLibrarySpans {
+				r.summary.Spans += len(ils.Spans)
+			}
+		}
+	case probe.ResourceMetrics != nil:
+		var req colmetricspb.ExportMetricsServiceRequest
+		if err := otlpjson.Unmarshal(line, &req); err != nil {
+			return err
+		}
+		if !r.opts.DryRun {
+			if r.opts.Metrics == nil {
+				return fmt.Errorf("metrics: %w", ErrNoClient)
+			}
+			if err := r.wait(ctx); err != nil {
+				return err
+			}
+			if err := r.opts.Metrics.UploadMetrics(ctx, req.ResourceMetrics); err != nil {
+				return err
+			}
+		}
+		for _, rm := range req.ResourceMetrics {
+			for _, ilm := range rm.InstrumentationLibraryMetrics {
+				r.summary.Metrics += len(ilm.Metrics)
+			}
+		}
+	default:
+		return ErrUnsupportedLine
+	}
+	r.summary.Lines++
+	return nil
+}
+
+// wait blocks until the next line is allowed to be sent by the rate limit
+func (r *replayer) wait(ctx context.Context) error {
+	if r.opts.Rate <= 0 {
+		return nil
+	}
+	if d := time.Until(r.next); d > 0 {
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	r.next = time.Now().Add(time.Duration(float64(time.Second) / r.opts.Rate))
+	return nil
+}
+
+func readCheckpoint(path string) (map[string]position, error) {
+	positions := make(map[string]position)
+	if path == "" {
+		return positions, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return positions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &positions); err != nil {
+		return nil, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	return positions, nil
+}
+
+// writeCheckpoint replaces the checkpoint using a rename
+// so that it is not left partially written
+func writeCheckpoint(path string, positions map[string]position) error {
+	if path == "" {
+		return nil
+	}
+	b, err := json.Marshal(positions)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,231 @@
+package replay_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/metric"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/trace"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/replay"
+	"github.com/MovieStoreGuy/otel-go-starter/otelstartertest/collector"
+)
+
+const (
+	tracesLine  = `{"resourceSpans":[{"instrumentationLibrarySpans":[{"spans":[{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","spanId":"00f067aa0ba902b7","name":"checkout"}]}]}]}`
+	metricsLine = `{"resourceMetrics":[{"instrumentationLibraryMetrics":[{"metrics":[{"name":"orders","sum":{"dataPoints":[{"asInt":"3"}],"aggregationTemporality":"AGGREGATION_TEMPORALITY_CUMULATIVE","isMonotonic":true}}]}]}]}`
+	logsLine    = `{"resourceLogs":[]}`
+)
+
+func writeLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.WriteString(strings.Join(lines, "\n") + "\n")
+	require.NoError(t, err)
+}
+
+// newOptions creates the clients sending to the collector
+func newOptions(t *testing.T, c *collector.Collector) replay.Options {
+	t.Helper()
+
+	traces, err := trace.NewClient(&config.Export{Named: "otlpgrpc", Endpoint: c.GRPCEndpoint()})
+	require.NoError(t, err)
+	metrics, err := metric.NewClient(&config.Export{Named: "otlphttp", Endpoint: c.HTTPEndpoint()})
+	require.NoError(t, err)
+	return replay.Options{Traces: traces, Metrics: metrics}
+}
+
+func TestReplayResumesFromCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c    = collector.New(t)
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "telemetry.jsonl")
+		ctx  = context.Background()
+	)
+	writeLines(t, path, tracesLine, "", metricsLine, tracesLine)
+
+	opts := newOptions(t, c)
+	opts.Checkpoint = filepath.Join(dir, "checkpoint.json")
+
+	summary, err := replay.Replay(ctx, opts, path)
+	require.NoError(t, err, "Must replay the file")
+	assert.Equal(t, replay.Summary{Lines: 3, Spans: 2, Metrics: 1}, summary)
+	require.Len(t, c.Requests(), 3, "Must send each line as a request")
+	assert.Equal(t, collector.ProtocolGRPC, c.Requests()[0].Protocol)
+	assert.Equal(t, collector.ProtocolHTTP, c.Requests()[1].Protocol)
+	if spans := c.Spans(); assert.Len(t, spans, 2) {
+		assert.Equal(t, "checkout", spans[0].Name)
+	}
+
+	summary, err = replay.Replay(ctx, newOptions(t, c), path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Lines, "Must replay the whole file without a checkpoint")
+
+	opts = newOptions(t, c)
+	opts.Checkpoint = filepath.Join(dir, "checkpoint.json")
+	summary, err = replay.Replay(ctx, opts, path)
+	require.NoError(t, err)
+	assert.Equal(t, replay.Summary{}, summary, "Must not resend lines recorded in the checkpoint")
+
+	writeLines(t, path, metricsLine)
+	opts = newOptions(t, c)
+	opts.Checkpoint = filepath.Join(dir, "checkpoint.json")
+	summary, err = replay.Replay(ctx, opts, path)
+	require.NoError(t, err)
+	assert.Equal(t, replay.Summary{Lines: 1, Metrics: 1}, summary, "Must only send the lines written since the checkpoint")
+	assert.Len(t, c.Requests(), 7)
+}
+
+func TestReplayRestartsRotatedFile(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c          = collector.New(t)
+		dir        = t.TempDir()
+		path       = filepath.Join(dir, "telemetry.jsonl")
+		checkpoint = filepath.Join(dir, "checkpoint.json")
+		ctx        = context.Background()
+	)
+	replayFile := func() replay.Summary {
+		t.Helper()
+
+		opts := newOptions(t, c)
+		opts.Checkpoint = checkpoint
+		summary, err := replay.Replay(ctx, opts, path)
+		require.NoError(t, err)
+		return summary
+	}
+
+	writeLines(t, path, tracesLine, tracesLine, metricsLine)
+	assert.Equal(t, replay.Summary{Lines: 3, Spans: 2, Metrics: 1}, replayFile())
+
+	require.NoError(t, os.Rename(path, path+".1"))
+	writeLines(t, path, metricsLine)
+	assert.Equal(t, replay.Summary{Lines: 1, Metrics: 1}, replayFile(), "Must replay a file smaller than the checkpoint from the start")
+
+	require.NoError(t, os.Rename(path, path+".2"))
+	writeLines(t, path, tracesLine, metricsLine, metricsLine, metricsLine)
+	assert.Equal(t, replay.Summary{Lines: 4, Spans: 1, Metrics: 3}, replayFile(), "Must replay a file with a different first line from the start")
+
+	assert.Equal(t, replay.Summary{}, replayFile(), "Must resume the replaced file from its checkpoint")
+}
+
+func TestReplayStopsAtInvalidLine(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c    = collector.New(t)
+		dir  = t.TempDir()
+		path = filepath.Join(dir, "telemetry.jsonl")
+	)
+	writeLines(t, path, tracesLine, logsLine, tracesLine)
+
+	opts := newOptions(t, c)
+	opts.Checkpoint = filepath.Join(dir, "checkpoint.json")
+
+	summary, err := replay.Replay(context.Background(), opts, path)
+	assert.ErrorIs(t, err, replay.ErrUnsupportedLine)
+	assert.Contains(t, err.Error(), "telemetry.jsonl:2:", "Must report the invalid line")
+	assert.Equal(t, 1, summary.Lines)
+	assert.Len(t, c.Requests(), 1, "Must not send the lines after the invalid line")
+
+	checkpoint, err := os.ReadFile(opts.Checkpoint)
+	require.NoError(t, err)
+	assert.Contains(t, string(checkpoint), `"line":1`, "Must only record the lines that were sent")
+}
+
+func TestReplayDryRun(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dir   = t.TempDir()
+		path  = filepath.Join(dir, "telemetry.jsonl")
+		other = filepath.Join(dir, "other.jsonl")
+	)
+	writeLines(t, path, tracesLine, "{", metricsLine)
+	writeLines(t, other, logsLine, tracesLine)
+
+	summary, err := replay.Replay(context.Background(), replay.Options{
+		DryRun:     true,
+		Checkpoint: filepath.Join(dir, "checkpoint.json"),
+	}, path, other)
+
+	assert.Equal(t, replay.Summary{Lines: 3, Spans: 2, Metrics: 1}, summary, "Must count the valid lines")
+	errs := multierr.Errors(err)
+	require.Len(t, errs, 2, "Must report every invalid line")
+	assert.Contains(t, errs[0].Error(), "telemetry.jsonl:2:")
+	assert.Contains(t, errs[1].Error(), "other.jsonl:1:")
+	assert.ErrorIs(t, errs[1], replay.ErrUnsupportedLine)
+	assert.NoFileExists(t, filepath.Join(dir, "checkpoint.json"), "Must not write the checkpoint for a dry run")
+}
+
+func TestReplayRateLimit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		c    = collector.New(t)
+		path = filepath.Join(t.TempDir(), "telemetry.jsonl")
+	)
+	writeLines(t, path, tracesLine, tracesLine, tracesLine)
+
+	opts := newOptions(t, c)
+	opts.Rate = 20
+
+	start := time.Now()
+	summary, err := replay.Replay(context.Background(), opts, path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Lines)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "Must wait between each line")
+}
+
+func TestReplayMissingClient(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	writeLines(t, path, metricsLine)
+
+	_, err := replay.Replay(context.Background(), replay.Options{}, path)
+	assert.ErrorIs(t, err, replay.ErrNoClient)
+}
+
+// stopRecorder is a traces client that records the context it was stopped with
+type stopRecorder struct {
+	stopErr error
+}
+
+func (sr *stopRecorder) Start(context.Context) error { return nil }
+func (sr *stopRecorder) Stop(ctx context.Context) error {
+	sr.stopErr = ctx.Err()
+	return nil
+}
+func (sr *stopRecorder) UploadTraces(context.Context, []*tracepb.ResourceSpans) error { return nil }
+
+func TestReplayStopsAfterCancel(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	writeLines(t, path, tracesLine)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := &stopRecorder{}
+	_, err := replay.Replay(ctx, replay.Options{Traces: client}, path)
+	require.NoError(t, err)
+	assert.NoError(t, client.stopErr, "Must stop the clients with a context that is not cancelled")
+}
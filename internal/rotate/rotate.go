@@ -0,0 +1,163 @@
+// Package rotate provides an append only file that is rotated
+// once it reaches a maximum size, as used by the file exporters.
+package rotate
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+)
+
+// timeFormat is used for the suffix of rotated files,
+// it sorts in the order the files were rotated
+const timeFormat = "20060102T150405.000000000"
+
+// File appends each write to the file at its path, moving the file
+// to a backup named with the time it was rotated once a write
+// would take it over the configured maximum size
+type File struct {
+	mu   sync.Mutex
+	path string
+	conf config.File
+	f    *os.File
+	size int64
+
+	// closed stops writes from reopening the file once it has been closed
+	closed bool
+}
+
+// Open opens the file at path for appending, creating it if it does not exist
+func Open(path string, conf config.File) (*File, error) {
+	rf := &File{path: path, conf: conf}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// Write writes p to the file in a single write so that a line is never
+// split across rotated files, the file is synced afterwards when configured
+func (rf *File) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.closed {
+		return 0, &os.PathError{Op: "write", Path: rf.path, Err: os.ErrClosed}
+	}
+	// A failed rotation leaves the file closed, reopening it allows
+	// writes to continue once the cause of the failure is resolved
+	if rf.f == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+	if rf.conf.MaxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.conf.MaxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if rf.conf.SyncEachExport {
+		return n, rf.f.Sync()
+	}
+	return n, nil
+}
+
+// Close flushes the file to disk and closes it,
+// it is safe to call more than once
+func (rf *File) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	f := rf.f
+	rf.f, rf.closed = nil, true
+	if f == nil {
+		return nil
+	}
+	return multierr.Append(f.Sync(), f.Close())
+}
+
+func (rf *File) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rf.f, rf.size = f, info.Size()
+	return nil
+}
+
+// rotate moves the file to a backup and opens a new file at the path,
+// the original file is reopened when it could not be moved
+func (rf *File) rotate() error {
+	err := rf.f.Close()
+	rf.f = nil
+	if err == nil {
+		err = os.Rename(rf.path, rf.path+"."+time.Now().UTC().Format(timeFormat))
+	}
+	if oerr := rf.open(); oerr != nil {
+		return multierr.Append(err, oerr)
+	}
+	if err != nil {
+		return err
+	}
+	return rf.removeBackups()
+}
+
+// removeBackups deletes the oldest rotated files beyond the configured maximum
+func (rf *File) removeBackups() error {
+	if rf.conf.MaxBackups == 0 {
+		return nil
+	}
+	backups, err := Backups(rf.path)
+	if err != nil {
+		return err
+	}
+	for len(backups) > rf.conf.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Backups returns the paths of the files rotated from path, oldest first
+func Backups(path string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	var (
+		prefix  = filepath.Base(path) + "."
+		backups []string
+	)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, err := time.Parse(timeFormat, strings.TrimPrefix(name, prefix)); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(filepath.Dir(path), name))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
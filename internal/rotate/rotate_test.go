@@ -0,0 +1,113 @@
+package rotate_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/rotate"
+)
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestFileAppends(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0o644))
+
+	f, err := rotate.Open(path, config.File{SyncEachExport: true})
+	require.NoError(t, err)
+	_, err = f.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "existing\nfirst\n", read(t, path), "Must append to the existing file")
+	backups, err := rotate.Backups(path)
+	require.NoError(t, err)
+	assert.Empty(t, backups, "Must not rotate without a max size")
+}
+
+func TestFileClosed(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	f, err := rotate.Open(path, config.File{})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.NoError(t, f.Close(), "Must not error when closed more than once")
+
+	_, err = f.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, os.ErrClosed, "Must not write once closed")
+	assert.Empty(t, read(t, path), "Must not reopen the file once closed")
+}
+
+func TestFileRotates(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "spans.jsonl")
+	// Files that only share the prefix must not be treated as backups
+	require.NoError(t, os.WriteFile(path+".old", []byte("kept"), 0o644))
+
+	f, err := rotate.Open(path, config.File{MaxSize: 12, MaxBackups: 2})
+	require.NoError(t, err)
+	for _, line := range []string{"line-1\n", "line-2\n", "line-3\n", "line-4\n", "a-much-longer-line\n"} {
+		_, err := f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "a-much-longer-line\n", read(t, path), "Must write lines larger than the max size to an empty file")
+
+	backups, err := rotate.Backups(path)
+	require.NoError(t, err)
+	require.Len(t, backups, 2, "Must remove the oldest backups")
+	assert.Equal(t, "line-3\n", read(t, backups[0]))
+	assert.Equal(t, "line-4\n", read(t, backups[1]))
+	for _, b := range backups {
+		assert.True(t, strings.HasPrefix(filepath.Base(b), "spans.jsonl."))
+	}
+	assert.Equal(t, "kept", read(t, path+".old"))
+}
+
+func TestFileRecoversFromFailedRotation(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "telemetry")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	path := filepath.Join(dir, "spans.jsonl")
+
+	f, err := rotate.Open(path, config.File{MaxSize: 8})
+	require.NoError(t, err)
+	_, err = f.Write([]byte("line-1\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.RemoveAll(dir))
+	_, err = f.Write([]byte("line-2\n"))
+	assert.Error(t, err, "Must error when the file can not be rotated")
+
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	_, err = f.Write([]byte("line-3\n"))
+	require.NoError(t, err, "Must reopen the file once it can be written to")
+	require.NoError(t, f.Close())
+	assert.Equal(t, "line-3\n", read(t, path))
+}
+
+func TestOpenInvalidPath(t *testing.T) {
+	t.Parallel()
+
+	_, err := rotate.Open(filepath.Join(t.TempDir(), "missing", "spans.jsonl"), config.File{})
+	assert.Error(t, err, "Must error when the directory does not exist")
+}
@@ -2,121 +2,451 @@ package otelstarter
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
+	otelmetric "go.opentelemetry.io/otel/metric"
 	metricglobal "go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
 	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
-	processor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
-	selector "go.opentelemetry.io/otel/sdk/metric/selector/simple"
+	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 
 	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/exporters"
+	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/log"
 	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/metric"
 	"github.com/MovieStoreGuy/otel-go-starter/internal/pipeline/trace"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
 )
 
 // Launcher stores all the information used from configuring the global
 // Open Telemetry properties and allows for graceful shutdowns
 type Launcher interface {
+	// Shutdown calls ShutdownContext with a default deadline
+	// and reports any errors to the configured error handler
 	Shutdown()
+	// ForceFlush exports any buffered spans and log records and collects
+	// and exports the current metrics without stopping the pipelines,
+	// any errors are returned as *PipelineError values combined using multierr
+	ForceFlush(ctx context.Context) error
+	// ShutdownContext flushes the tracer, meter and logger providers then shuts down
+	// the pipelines in the reverse order they were started within the deadline of ctx,
+	// any errors are returned as *PipelineError values combined using multierr
+	ShutdownContext(ctx context.Context) error
+
+	// TracerProvider returns the provider used by the tracing pipeline,
+	// or a no-op provider when the pipeline is not enabled
+	TracerProvider() oteltrace.TracerProvider
+	// MeterProvider returns the provider used by the metrics pipeline,
+	// or a no-op provider when the pipeline is not enabled
+	MeterProvider() otelmetric.MeterProvider
+	// LoggerProvider returns the provider used by the logs pipeline,
+	// or a no-op provider when the pipeline is not enabled
+	LoggerProvider() logs.LoggerProvider
+	// Propagator returns the propagators configured by the tracing pipeline,
+	// or an empty propagator when the pipeline is not enabled
+	Propagator() propagation.TextMapPropagator
 }
 
+// shutdownTimeout is the deadline used by Shutdown
+const shutdownTimeout = 5 * time.Second
+
 type launch struct {
-	shutdownCallbacks []func() error
+	handler otel.ErrorHandler
+
+	mu        sync.Mutex
+	flushers  []callback
+	shutdowns []callback
+
+	pusher *metric.Pusher
+	tp     *sdktrace.TracerProvider
+	lp     *log.Provider
+
+	// resource is shared by the pipelines and includes any detected attributes
+	resource       *resource.Resource
+	tracerProvider oteltrace.TracerProvider
+	meterProvider  otelmetric.MeterProvider
+	loggerProvider logs.LoggerProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// callback is a flush or shutdown step of a pipeline
+type callback struct {
+	pipeline Pipeline
+	fn       func(ctx context.Context) error
 }
 
 // Start configures the global context of the open telemetry functionality
 // Any issues trying to configure any of the instrumentation will cause the method to panic
 func Start(ctx context.Context, opts ...config.OptionFunc) Launcher {
+	l, err := New(ctx, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// New configures the global context of the open telemetry functionality
+// using the OTEL_* environment variables overridden by the provided options,
+// and returns a *PipelineError identifying the failed pipeline and stage on failure.
+// Using config.WithFailurePolicy(config.FailurePolicyNoop) will report failed
+// pipelines to the error handler and replace them with no-op implementations instead.
+// Using config.WithoutGlobals() leaves the global providers unchanged,
+// the configured providers are then accessed using the Launcher.
+func New(ctx context.Context, opts ...config.OptionFunc) (Launcher, error) {
 	c := config.NewDefault()
 
-	if err := c.Apply(opts...); err != nil {
-		panic(err)
+	if err := c.Apply(append([]config.OptionFunc{config.FromEnvironment()}, opts...)...); err != nil {
+		return nil, &PipelineError{Pipeline: PipelineConfig, Stage: StageConfigure, Err: err}
 	}
 
-	l := &launch{}
+	l := &launch{
+		handler:        c.GetErrorHandler(),
+		tracerProvider: oteltrace.NewNoopTracerProvider(),
+		meterProvider:  otelmetric.NewNoopMeterProvider(),
+		loggerProvider: logs.NewNoopLoggerProvider(),
+		propagator:     propagation.NewCompositeTextMapPropagator(),
+	}
 
-	otel.SetErrorHandler(c.GetErrorHandler())
+	if !c.DisableGlobals {
+		otel.SetErrorHandler(c.GetErrorHandler())
+	}
+
+	res, err := l.newResource(ctx, c)
+	if err != nil {
+		if err = l.fail(c, &PipelineError{Pipeline: PipelineConfig, Stage: StageConfigure, Err: err}); err != nil {
+			return nil, err
+		}
+		res = c.GetResource()
+	}
+	l.resource = res
 
 	if c.Metrics.Enable {
-		exporter, err := metric.NewExporterFactory().NewExporter(ctx, &c.Metrics.Export)
+		if err := l.startMetrics(ctx, c); err != nil {
+			if err = l.fail(c, err); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if c.Tracing.Enable {
+		prop, err := trace.NewPropagators(c.Tracing.Propagators)
 		if err != nil {
-			panic(err)
+			if err = l.fail(c, &PipelineError{Pipeline: PipelinePropagators, Stage: StageConfigure, Err: err}); err != nil {
+				return nil, err
+			}
+			prop = propagation.NewCompositeTextMapPropagator()
 		}
-		if sh, ok := exporter.(metric.ShutdownExporter); ok {
-			l.shutdownCallbacks = append(l.shutdownCallbacks, gracefulShutdown(sh.Shutdown))
+
+		if err := l.startTracing(ctx, c); err != nil {
+			if err = l.fail(c, err); err != nil {
+				return nil, err
+			}
 		}
+		l.propagator = prop
+	}
 
-		pusher := controller.New(
-			processor.NewFactory(
-				selector.NewWithInexpensiveDistribution(),
-				exporter,
-			),
-			controller.WithExporter(exporter),
-			controller.WithResource(c.GetResource()),
-			controller.WithCollectPeriod(time.Second),
-		)
+	if c.Logs.Enable {
+		if err := l.startLogs(ctx, c); err != nil {
+			if err = l.fail(c, err); err != nil {
+				return nil, err
+			}
+		}
+	}
 
-		if err := pusher.Start(ctx); err != nil {
-			panic(err)
+	if !c.DisableGlobals {
+		// Globals are only set once all the pipelines have started
+		// so that a failed launch does not leave them partially configured
+		if c.Metrics.Enable {
+			metricglobal.SetMeterProvider(l.meterProvider)
+		}
+		if c.Tracing.Enable {
+			otel.SetTracerProvider(l.tracerProvider)
+			otel.SetTextMapPropagator(l.propagator)
 		}
+		if c.Logs.Enable {
+			logs.SetLoggerProvider(l.loggerProvider)
+		}
+	}
 
-		l.shutdownCallbacks = append(l.shutdownCallbacks, gracefulShutdown(pusher.Stop))
-		metricglobal.SetMeterProvider(pusher)
+	return l, nil
+}
+
+// newResource runs the configured detectors and reports the detectors that disagree,
+// all conflicts are reported when config.WithResourceConflictReporting has been used
+func (l *launch) newResource(ctx context.Context, c *config.Config) (*resource.Resource, error) {
+	if err := c.DetectResources(ctx); err != nil {
+		return nil, err
+	}
+	report := c.ResourceReport()
+	err := report.DetectorErr()
+	if c.ReportResourceConflicts {
+		err = report.Err()
+	}
+	if err != nil {
+		l.handler.Handle(err)
 	}
+	return c.GetResource(), nil
+}
 
-	if c.Tracing.Enable {
-		exporter, err := trace.NewExporterFactory().NewExporter(ctx, &c.Tracing.Export)
+func (l *launch) startMetrics(ctx context.Context, c *config.Config) error {
+	var fanout metric.FanoutExporter
+	err := l.newExporters(c, PipelineMetrics, c.Metrics.AllExports(), func(export *config.Export) (shutdownFunc, error) {
+		exporter, err := exporters.NewMetricExporter(ctx, export)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
-		l.shutdownCallbacks = append(l.shutdownCallbacks, gracefulShutdown(exporter.Shutdown))
+		fanout = append(fanout, exporter)
+		if sh, ok := exporter.(metric.ShutdownExporter); ok {
+			return sh.Shutdown, nil
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var exporter sdkmetric.Exporter = fanout
+	if len(fanout) == 1 {
+		exporter = fanout[0]
+	}
+	if sh, ok := exporter.(metric.ShutdownExporter); ok {
+		l.shutdowns = append(l.shutdowns, callback{PipelineMetrics, sh.Shutdown})
+	}
+
+	// The controller is not started since it does not allow collecting
+	// while it is running, the pusher collects and exports instead
+	cont := controller.New(
+		metric.NewProcessorFactory(exporter),
+		controller.WithResource(l.resource),
+		controller.WithCollectPeriod(0),
+	)
+	pusher := metric.NewPusher(cont, exporter, c.Metrics.CollectPeriod, c.Metrics.PushTimeout, l.handler)
+
+	// Stopping the pusher performs a final collection and export
+	// so it is used to flush the pipeline before it is shutdown
+	l.flushers = append(l.flushers, callback{PipelineMetrics, pusher.Stop})
+	l.pusher = pusher
+	l.meterProvider = cont
+	return nil
+}
+
+func (l *launch) startTracing(ctx context.Context, c *config.Config) error {
+	sampler, err := trace.NewSampler(&c.Tracing)
+	if err != nil {
+		return &PipelineError{Pipeline: PipelineTracing, Stage: StageConfigure, Err: err}
+	}
 
-		var sampler sdktrace.Sampler
-		if c.Tracing.Sample {
-			sampler = sdktrace.AlwaysSample()
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sampler),
+		sdktrace.WithResource(l.resource),
+		sdktrace.WithSpanLimits(trace.NewSpanLimits(c.Tracing.Limits)),
+	}
+	var created []sdktrace.SpanExporter
+	err = l.newExporters(c, PipelineTracing, c.Tracing.AllExports(), func(export *config.Export) (shutdownFunc, error) {
+		exporter, err := exporters.NewTraceExporter(ctx, export)
+		if err != nil {
+			return nil, err
 		}
+		created = append(created, exporter)
+		return exporter.Shutdown, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, exporter := range created {
+		processor := trace.NewTruncatingProcessor(newSpanProcessor(&c.Tracing, exporter), c.Tracing.Limits.AttributeValueLength)
+		opts = append(opts, sdktrace.WithSpanProcessor(processor))
+	}
 
-		tp := sdktrace.NewTracerProvider(
-			sdktrace.WithSampler(sampler),
-			sdktrace.WithSpanProcessor(
-				sdktrace.NewBatchSpanProcessor(exporter),
-			),
-			sdktrace.WithResource(c.GetResource()),
-		)
+	tp := sdktrace.NewTracerProvider(opts...)
 
-		l.shutdownCallbacks = append(l.shutdownCallbacks, gracefulShutdown(tp.Shutdown))
+	l.flushers = append(l.flushers, callback{PipelineTracing, tp.ForceFlush})
+	l.tp = tp
+	// Shutting down the provider also shuts down each processor's exporter
+	l.shutdowns = append(l.shutdowns, callback{PipelineTracing, tp.Shutdown})
+	l.tracerProvider = tp
+	return nil
+}
 
-		prop, err := trace.NewPropagators(c.Tracing.Propagators)
+// newSpanProcessor creates the batch span processor for the exporter,
+// or a simple span processor when synchronous exports are configured
+func newSpanProcessor(t *config.Tracing, exporter sdktrace.SpanExporter) sdktrace.SpanProcessor {
+	if t.Synchronous {
+		return sdktrace.NewSimpleSpanProcessor(exporter)
+	}
+
+	var (
+		opts []sdktrace.BatchSpanProcessorOption
+		b    = t.Batch
+	)
+	if b.MaxQueueSize > 0 {
+		opts = append(opts, sdktrace.WithMaxQueueSize(b.MaxQueueSize))
+	}
+	if b.MaxExportBatchSize > 0 {
+		opts = append(opts, sdktrace.WithMaxExportBatchSize(b.MaxExportBatchSize))
+	}
+	if b.Timeout > 0 {
+		opts = append(opts, sdktrace.WithBatchTimeout(b.Timeout))
+	}
+	if b.ExportTimeout > 0 {
+		opts = append(opts, sdktrace.WithExportTimeout(b.ExportTimeout))
+	}
+	if b.BlockOnQueueFull {
+		opts = append(opts, sdktrace.WithBlocking())
+	}
+	return sdktrace.NewBatchSpanProcessor(exporter, opts...)
+}
 
+func (l *launch) startLogs(ctx context.Context, c *config.Config) error {
+	var created []logs.Exporter
+	err := l.newExporters(c, PipelineLogs, c.Logs.AllExports(), func(export *config.Export) (shutdownFunc, error) {
+		exporter, err := exporters.NewLogExporter(ctx, export)
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
+		created = append(created, exporter)
+		return exporter.Shutdown, nil
+	})
+	if err != nil {
+		return err
+	}
+	processors := make([]*log.BatchProcessor, 0, len(created))
+	for _, exporter := range created {
+		processors = append(processors, log.NewBatchProcessor(exporter, c.Logs.Batch, l.handler))
+	}
+	lp := log.NewProvider(l.resource, processors...)
+
+	l.flushers = append(l.flushers, callback{PipelineLogs, lp.ForceFlush})
+	l.lp = lp
+	// Shutting down the provider also shuts down each processor's exporter
+	l.shutdowns = append(l.shutdowns, callback{PipelineLogs, lp.Shutdown})
+	l.loggerProvider = lp
+	return nil
+}
 
-		otel.SetTextMapPropagator(prop)
-		otel.SetTracerProvider(tp)
+// shutdownFunc releases an exporter, it is nil when there is nothing to release
+type shutdownFunc func(ctx context.Context) error
+
+// newExporters calls create for each of the configured exports,
+// the tracing and logs pipelines then give each exporter its own processor
+// so that a slow or broken exporter does not block the others.
+// An exporter failing to be created stops the pipeline under FailurePolicyAbort,
+// otherwise it is reported to the error handler and only causes an error
+// once none of the exporters could be created.
+func (l *launch) newExporters(c *config.Config, pipeline Pipeline, exports []config.Export, create func(export *config.Export) (shutdownFunc, error)) error {
+	var (
+		created []shutdownFunc
+		errs    error
+	)
+	for i := range exports {
+		shutdown, err := create(&exports[i])
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		created = append(created, shutdown)
+	}
+	if errs == nil {
+		return nil
+	}
+	if len(created) == 0 || c.OnFailure == config.FailurePolicyAbort {
+		ctx, done := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer done()
+		for _, shutdown := range created {
+			if shutdown != nil {
+				errs = multierr.Append(errs, shutdown(ctx))
+			}
+		}
+		return &PipelineError{Pipeline: pipeline, Stage: StageExporter, Err: errs}
 	}
+	l.handler.Handle(&PipelineError{Pipeline: pipeline, Stage: StageExporter, Err: errs})
+	return nil
+}
 
-	return l
+// fail applies the configured failure policy to the pipeline error,
+// returning the error if the launcher must not continue.
+func (l *launch) fail(c *config.Config, err error) error {
+	if c.OnFailure == config.FailurePolicyNoop {
+		l.handler.Handle(err)
+		return nil
+	}
+	l.Shutdown()
+	return err
 }
 
 func (l *launch) Shutdown() {
-	var err error
-	for _, shutdown := range l.shutdownCallbacks {
-		err = multierr.Append(err, shutdown())
+	ctx, done := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer done()
+
+	if err := l.ShutdownContext(ctx); err != nil {
+		l.handler.Handle(err)
 	}
-	if err != nil {
-		otel.Handle(err)
+}
+
+func (l *launch) TracerProvider() oteltrace.TracerProvider {
+	return l.tracerProvider
+}
+
+func (l *launch) MeterProvider() otelmetric.MeterProvider {
+	return l.meterProvider
+}
+
+func (l *launch) LoggerProvider() logs.LoggerProvider {
+	return l.loggerProvider
+}
+
+func (l *launch) Propagator() propagation.TextMapPropagator {
+	return l.propagator
+}
+
+func (l *launch) ForceFlush(ctx context.Context) (err error) {
+	// The lock is only held to read the providers so that a slow
+	// exporter does not block a shutdown running at the same time
+	l.mu.Lock()
+	tp, lp, pusher := l.tp, l.lp, l.pusher
+	l.mu.Unlock()
+
+	if tp != nil {
+		if ferr := tp.ForceFlush(ctx); ferr != nil {
+			err = multierr.Append(err, &PipelineError{Pipeline: PipelineTracing, Stage: StageFlush, Err: ferr})
+		}
+	}
+	if lp != nil {
+		if ferr := lp.ForceFlush(ctx); ferr != nil {
+			err = multierr.Append(err, &PipelineError{Pipeline: PipelineLogs, Stage: StageFlush, Err: ferr})
+		}
 	}
+	if pusher != nil {
+		if ferr := pusher.Collect(ctx); ferr != nil {
+			err = multierr.Append(err, &PipelineError{Pipeline: PipelineMetrics, Stage: StageFlush, Err: ferr})
+		}
+	}
+	return err
 }
 
-func gracefulShutdown(f func(ctx context.Context) error) func() error {
-	return func() error {
-		ctx, done := context.WithTimeout(context.Background(), time.Second)
-		defer done()
+func (l *launch) ShutdownContext(ctx context.Context) (err error) {
+	l.mu.Lock()
+	flushers, shutdowns := l.flushers, l.shutdowns
+	l.flushers, l.shutdowns = nil, nil
+	l.pusher, l.tp, l.lp = nil, nil, nil
+	l.mu.Unlock()
 
-		return f(ctx)
+	for _, cb := range flushers {
+		if ferr := cb.fn(ctx); ferr != nil {
+			err = multierr.Append(err, &PipelineError{Pipeline: cb.pipeline, Stage: StageFlush, Err: ferr})
+		}
+	}
+	for i := len(shutdowns) - 1; i >= 0; i-- {
+		cb := shutdowns[i]
+		if serr := cb.fn(ctx); serr != nil {
+			err = multierr.Append(err, &PipelineError{Pipeline: cb.pipeline, Stage: StageShutdown, Err: serr})
+		}
 	}
+	return err
 }
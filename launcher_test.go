@@ -2,14 +2,29 @@ package otelstarter_test
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	metricglobal "go.opentelemetry.io/otel/metric/global"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	launcher "github.com/MovieStoreGuy/otel-go-starter"
 	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/detectors"
+	"github.com/MovieStoreGuy/otel-go-starter/exporters"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
 )
 
 type OtelTestHandler struct {
@@ -22,6 +37,14 @@ func (ot *OtelTestHandler) Handle(err error) {
 	assert.NoError(ot.T, err, "Must not error throughout testing")
 }
 
+var registered int64
+
+// exporterName returns a unique exporter name
+// since the exporter registry is shared between tests
+func exporterName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, atomic.AddInt64(&registered, 1))
+}
+
 func TestLauncherUsingDefault(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -78,3 +101,761 @@ func TestLauncherPanicsWithInvalidTracingConfig(t *testing.T) {
 		))
 	})
 }
+
+func TestLauncherReturnsPipelineErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	testCases := []struct {
+		scenario string
+		opts     []config.OptionFunc
+		pipeline launcher.Pipeline
+		stage    launcher.Stage
+	}{
+		{
+			scenario: "Invalid configuration",
+			opts:     []config.OptionFunc{config.WithResourceDetector(ctx, nil)},
+			pipeline: launcher.PipelineConfig,
+			stage:    launcher.StageConfigure,
+		},
+		{
+			scenario: "Unknown metrics exporter",
+			opts: []config.OptionFunc{config.WithMetricsPipeline(
+				config.WithMetricsExporterOptions(config.WithExporterNamed("unsupported-exporter")),
+			)},
+			pipeline: launcher.PipelineMetrics,
+			stage:    launcher.StageExporter,
+		},
+		{
+			scenario: "Unknown tracing exporter",
+			opts: []config.OptionFunc{config.WithTracesPipeline(
+				config.WithTracingExporterOptions(config.WithExporterNamed("unsupported-exporter")),
+			)},
+			pipeline: launcher.PipelineTracing,
+			stage:    launcher.StageExporter,
+		},
+		{
+			scenario: "Unknown propagator",
+			opts: []config.OptionFunc{config.WithTracesPipeline(
+				config.WithTracingExporterOptions(config.WithExporterNamed("stdout")),
+				config.WithTracingPropagators("excellent-propagator"),
+			)},
+			pipeline: launcher.PipelinePropagators,
+			stage:    launcher.StageConfigure,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			l, err := launcher.New(ctx, tc.opts...)
+			assert.Nil(t, l, "Must not return a launcher on failure")
+
+			var pe *launcher.PipelineError
+			if assert.ErrorAs(t, err, &pe, "Must return a pipeline error") {
+				assert.Equal(t, tc.pipeline, pe.Pipeline)
+				assert.Equal(t, tc.stage, pe.Stage)
+			}
+		})
+	}
+}
+
+func TestLauncherNoopFailurePolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []error
+	l, err := launcher.New(ctx,
+		config.WithOtelErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			handled = append(handled, err)
+		})),
+		config.WithFailurePolicy(config.FailurePolicyNoop),
+		config.WithTracesPipeline(
+			config.WithTracingExporterOptions(config.WithExporterNamed("unsupported-exporter")),
+		),
+	)
+	assert.NoError(t, err, "Must not error when using the no-op failure policy")
+	assert.NotNil(t, l, "Must return a valid launcher")
+	l.Shutdown()
+
+	if assert.Len(t, handled, 1, "Must report the failed pipeline to the error handler") {
+		var pe *launcher.PipelineError
+		assert.ErrorAs(t, handled[0], &pe)
+	}
+}
+
+func TestLauncherWithMultipleExporters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var handled []error
+	l, err := launcher.New(ctx,
+		config.WithOtelErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			handled = append(handled, err)
+		})),
+		config.WithFailurePolicy(config.FailurePolicyNoop),
+		config.WithTracesPipeline(
+			config.WithTracingExporterOptions(config.WithExporterNamed("stdout")),
+			config.WithTracingAdditionalExporter(config.WithExporterNamed("unsupported-exporter")),
+		),
+		config.WithMetricsPipeline(
+			config.WithMetricsExporterOptions(config.WithExporterNamed("stdout")),
+			config.WithMetricsAdditionalExporter(config.WithExporterNamed("stdout")),
+		),
+	)
+	assert.NoError(t, err, "Must not error when at least one exporter is valid")
+	assert.NotNil(t, l, "Must return a valid launcher")
+	l.Shutdown()
+
+	if assert.Len(t, handled, 1, "Must report the broken exporter") {
+		var pe *launcher.PipelineError
+		if assert.ErrorAs(t, handled[0], &pe) {
+			assert.Equal(t, launcher.PipelineTracing, pe.Pipeline)
+			assert.Equal(t, launcher.StageExporter, pe.Stage)
+		}
+	}
+}
+
+func TestLauncherAbortsOnBrokenAdditionalExporter(t *testing.T) {
+	name := exporterName("launcher-abort-exporter")
+	exporter := &shutdownLogExporter{}
+	require.NoError(t, exporters.RegisterLog(name, func(context.Context, *config.Export) (logs.Exporter, error) {
+		return exporter, nil
+	}))
+
+	l, err := launcher.New(context.Background(),
+		config.WithoutGlobals(),
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithLogsPipeline(
+			config.WithLogsExporterOptions(config.WithExporterNamed(name)),
+			config.WithLogsAdditionalExporter(config.WithExporterNamed("unsupported-exporter")),
+		),
+	)
+	assert.Nil(t, l, "Must not return a launcher")
+	var pe *launcher.PipelineError
+	if assert.ErrorAs(t, err, &pe, "Must fail with the default abort policy") {
+		assert.Equal(t, launcher.PipelineLogs, pe.Pipeline)
+		assert.Equal(t, launcher.StageExporter, pe.Stage)
+	}
+	assert.True(t, exporter.isShutdown(), "Must shutdown the exporters that were created")
+}
+
+func TestLauncherWithRegisteredExporter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var created bool
+	name := exporterName("launcher-test-exporter")
+	require.NoError(t, exporters.RegisterTrace(name, func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+		created = true
+		return tracetest.NewNoopExporter(), nil
+	}))
+
+	l, err := launcher.New(ctx,
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithTracesPipeline(
+			config.WithTracingExporterOptions(config.WithExporterNamed(name)),
+		),
+	)
+	require.NoError(t, err, "Must not error using a registered exporter")
+	l.Shutdown()
+
+	assert.True(t, created, "Must have used the registered exporter")
+}
+
+// recordingExporter sends the time and context deadline of each export
+type recordingExporter struct {
+	exports chan recordedExport
+}
+
+type recordedExport struct {
+	at       time.Time
+	deadline time.Time
+}
+
+var _ sdkmetric.Exporter = (*recordingExporter)(nil)
+
+func (re *recordingExporter) Export(ctx context.Context, _ *resource.Resource, _ sdkmetric.InstrumentationLibraryReader) error {
+	deadline, _ := ctx.Deadline()
+	select {
+	case re.exports <- recordedExport{at: time.Now(), deadline: deadline}:
+	default:
+	}
+	return nil
+}
+
+func (re *recordingExporter) ExportKindFor(_ *otelmetric.Descriptor, _ aggregation.Kind) sdkmetric.ExportKind {
+	return sdkmetric.CumulativeExportKind
+}
+
+func TestLauncherUsesCollectPeriod(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const (
+		period  = 50 * time.Millisecond
+		timeout = 20 * time.Millisecond
+	)
+
+	recorder := &recordingExporter{exports: make(chan recordedExport, 10)}
+	name := exporterName("launcher-test-recorder")
+	require.NoError(t, exporters.RegisterMetric(name, func(_ context.Context, _ *config.Export) (sdkmetric.Exporter, error) {
+		return recorder, nil
+	}))
+
+	l, err := launcher.New(ctx,
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithMetricsPipeline(
+			config.WithMetricsExporterOptions(config.WithExporterNamed(name)),
+			config.WithMetricsCollectionPeriod(period),
+			config.WithMetricsPushTimeout(timeout),
+		),
+	)
+	require.NoError(t, err, "Must not error when starting the launcher")
+	defer l.Shutdown()
+
+	started := time.Now()
+	var exports []recordedExport
+	for len(exports) < 3 {
+		select {
+		case e := <-recorder.exports:
+			exports = append(exports, e)
+		case <-time.After(time.Second):
+			t.Fatal("Exporter was not called at the configured period")
+		}
+	}
+
+	assert.GreaterOrEqual(t, exports[2].at.Sub(started), 2*period, "Must not export faster than the collect period")
+	for i := 1; i < len(exports); i++ {
+		assert.GreaterOrEqual(t, exports[i].at.Sub(exports[i-1].at), period/2, "Must export at the collect period")
+	}
+	for _, e := range exports {
+		if assert.False(t, e.deadline.IsZero(), "Must set a deadline on the export") {
+			assert.LessOrEqual(t, e.deadline.Sub(e.at), timeout, "Must apply the push timeout")
+		}
+	}
+}
+
+func TestLauncherFlushesAfterStartContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	recorder := &recordingExporter{exports: make(chan recordedExport, 10)}
+	name := exporterName("launcher-test-cancelled")
+	require.NoError(t, exporters.RegisterMetric(name, func(_ context.Context, _ *config.Export) (sdkmetric.Exporter, error) {
+		return recorder, nil
+	}))
+
+	l, err := launcher.New(ctx,
+		config.WithoutGlobals(),
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithMetricsPipeline(
+			config.WithMetricsExporterOptions(config.WithExporterNamed(name)),
+			config.WithMetricsCollectionPeriod(20*time.Millisecond),
+		),
+	)
+	require.NoError(t, err, "Must not error when starting the launcher")
+	defer l.Shutdown()
+	cancel()
+
+	require.NoError(t, l.ForceFlush(context.Background()), "Must flush once the start context is cancelled")
+	for i := 0; i < 3; i++ {
+		select {
+		case <-recorder.exports:
+		case <-time.After(time.Second):
+			t.Fatal("Must keep exporting after flushing")
+		}
+	}
+}
+
+// orderedExporter records the order its methods are called in
+type orderedExporter struct {
+	mu    sync.Mutex
+	calls []string
+
+	shutdown func(ctx context.Context) error
+}
+
+var (
+	_ sdktrace.SpanExporter = (*orderedExporter)(nil)
+	_ sdkmetric.Exporter    = (*orderedExporter)(nil)
+)
+
+func (oe *orderedExporter) record(call string) {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	oe.calls = append(oe.calls, call)
+}
+
+func (oe *orderedExporter) Calls() []string {
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+	return append([]string(nil), oe.calls...)
+}
+
+func (oe *orderedExporter) ExportSpans(_ context.Context, _ []sdktrace.ReadOnlySpan) error {
+	oe.record("export")
+	return nil
+}
+
+func (oe *orderedExporter) Export(_ context.Context, _ *resource.Resource, _ sdkmetric.InstrumentationLibraryReader) error {
+	oe.record("export")
+	return nil
+}
+
+func (oe *orderedExporter) ExportKindFor(_ *otelmetric.Descriptor, _ aggregation.Kind) sdkmetric.ExportKind {
+	return sdkmetric.CumulativeExportKind
+}
+
+func (oe *orderedExporter) Shutdown(ctx context.Context) error {
+	oe.record("shutdown")
+	if oe.shutdown != nil {
+		return oe.shutdown(ctx)
+	}
+	return nil
+}
+
+func TestLauncherShutdownContextFlushes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	spans, metrics := &orderedExporter{}, &orderedExporter{}
+	traceName, metricName := exporterName("launcher-test-ordered"), exporterName("launcher-test-ordered")
+	require.NoError(t, exporters.RegisterTrace(traceName, func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+		return spans, nil
+	}))
+	require.NoError(t, exporters.RegisterMetric(metricName, func(_ context.Context, _ *config.Export) (sdkmetric.Exporter, error) {
+		return metrics, nil
+	}))
+
+	l, err := launcher.New(ctx,
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithTracesPipeline(config.WithTracingExporterOptions(config.WithExporterNamed(traceName))),
+		config.WithMetricsPipeline(
+			config.WithMetricsExporterOptions(config.WithExporterNamed(metricName)),
+			config.WithMetricsCollectionPeriod(time.Hour),
+		),
+	)
+	require.NoError(t, err, "Must not error when starting the launcher")
+
+	_, span := otel.Tracer("test").Start(ctx, "buffered")
+	span.End()
+
+	assert.NoError(t, l.ShutdownContext(ctx), "Must not error when shutting down")
+	assert.Equal(t, []string{"export", "shutdown"}, spans.Calls(), "Must flush buffered spans before shutting down")
+	assert.Equal(t, []string{"export", "shutdown"}, metrics.Calls(), "Must export metrics before shutting down")
+
+	assert.NoError(t, l.ShutdownContext(ctx), "Must not error when already shutdown")
+	assert.Len(t, spans.Calls(), 2, "Must only shutdown once")
+}
+
+func TestLauncherShutdownContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	blocked := &orderedExporter{shutdown: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	name := exporterName("launcher-test-blocked")
+	require.NoError(t, exporters.RegisterMetric(name, func(_ context.Context, _ *config.Export) (sdkmetric.Exporter, error) {
+		return blocked, nil
+	}))
+
+	var handled []error
+	l, err := launcher.New(ctx,
+		config.WithOtelErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			handled = append(handled, err)
+		})),
+		config.WithMetricsPipeline(config.WithMetricsExporterOptions(config.WithExporterNamed(name))),
+	)
+	require.NoError(t, err, "Must not error when starting the launcher")
+
+	sctx, done := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer done()
+
+	started := time.Now()
+	err = l.ShutdownContext(sctx)
+	assert.Less(t, time.Since(started), time.Second, "Must return once the deadline is reached")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Must return the shutdown error")
+
+	var pe *launcher.PipelineError
+	if assert.ErrorAs(t, err, &pe) {
+		assert.Equal(t, launcher.PipelineMetrics, pe.Pipeline)
+		assert.Equal(t, launcher.StageShutdown, pe.Stage)
+	}
+	assert.Empty(t, handled, "Must return errors instead of reporting them")
+}
+
+func TestLauncherForceFlush(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	spans, metrics := &orderedExporter{}, &orderedExporter{}
+	traceName, metricName := exporterName("launcher-test-flush"), exporterName("launcher-test-flush")
+	require.NoError(t, exporters.RegisterTrace(traceName, func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+		return spans, nil
+	}))
+	require.NoError(t, exporters.RegisterMetric(metricName, func(_ context.Context, _ *config.Export) (sdkmetric.Exporter, error) {
+		return metrics, nil
+	}))
+
+	l, err := launcher.New(ctx,
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithTracesPipeline(config.WithTracingExporterOptions(config.WithExporterNamed(traceName))),
+		config.WithMetricsPipeline(
+			config.WithMetricsExporterOptions(config.WithExporterNamed(metricName)),
+			config.WithMetricsCollectionPeriod(time.Hour),
+		),
+	)
+	require.NoError(t, err, "Must not error when starting the launcher")
+
+	for i := 1; i <= 2; i++ {
+		_, span := otel.Tracer("test").Start(ctx, "invocation")
+		span.End()
+
+		assert.NoError(t, l.ForceFlush(ctx), "Must not error when flushing")
+		assert.Len(t, spans.Calls(), i, "Must export the buffered spans")
+		assert.Len(t, metrics.Calls(), i, "Must collect and export the metrics")
+	}
+	assert.NotContains(t, spans.Calls(), "shutdown", "Must not shutdown the exporters")
+
+	assert.NoError(t, l.ShutdownContext(ctx), "Must be able to shutdown after flushing")
+	assert.Equal(t, "shutdown", metrics.Calls()[len(metrics.Calls())-1])
+	assert.NoError(t, l.ForceFlush(ctx), "Must not error flushing after shutdown")
+}
+
+func TestLauncherWithoutGlobals(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracerProvider, meterProvider, propagator := otel.GetTracerProvider(), metricglobal.GetMeterProvider(), otel.GetTextMapPropagator()
+
+	newLauncher := func(propagator string) (launcher.Launcher, *tracetest.InMemoryExporter) {
+		spans := tracetest.NewInMemoryExporter()
+		name := exporterName("launcher-test-tenant")
+		require.NoError(t, exporters.RegisterTrace(name, func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+			return spans, nil
+		}))
+
+		l, err := launcher.New(ctx,
+			config.WithoutGlobals(),
+			config.WithOtelErrorHandler(&OtelTestHandler{t}),
+			config.WithTracesPipeline(
+				config.WithTracingExporterOptions(config.WithExporterNamed(name)),
+				config.WithTracingPropagators(propagator),
+			),
+			config.WithMetricsPipeline(config.WithMetricsExporterOptions(config.WithExporterNamed("stdout"))),
+		)
+		require.NoError(t, err, "Must not error when starting the launcher")
+		return l, spans
+	}
+
+	first, firstSpans := newLauncher("tracecontext")
+	second, secondSpans := newLauncher("b3")
+
+	assert.Equal(t, tracerProvider, otel.GetTracerProvider(), "Must not replace the global tracer provider")
+	assert.Equal(t, meterProvider, metricglobal.GetMeterProvider(), "Must not replace the global meter provider")
+	assert.Equal(t, propagator, otel.GetTextMapPropagator(), "Must not replace the global propagator")
+
+	_, span := first.TracerProvider().Tracer("test").Start(ctx, "first")
+	span.End()
+	_, span = second.TracerProvider().Tracer("test").Start(ctx, "second")
+	span.End()
+	otelmetric.Must(first.MeterProvider().Meter("test")).NewInt64Counter("jobs").Add(ctx, 1)
+
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate"}, first.Propagator().Fields())
+	assert.Contains(t, second.Propagator().Fields(), "x-b3-traceid")
+
+	require.NoError(t, first.ForceFlush(ctx))
+	require.NoError(t, second.ForceFlush(ctx))
+
+	if assert.Len(t, firstSpans.GetSpans(), 1) {
+		assert.Equal(t, "first", firstSpans.GetSpans()[0].Name)
+	}
+	if assert.Len(t, secondSpans.GetSpans(), 1) {
+		assert.Equal(t, "second", secondSpans.GetSpans()[0].Name)
+	}
+
+	assert.NoError(t, first.ShutdownContext(ctx))
+	assert.NoError(t, second.ShutdownContext(ctx))
+}
+
+func TestLauncherProvidersWhenDisabled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l, err := launcher.New(ctx, config.WithoutGlobals(), config.WithOtelErrorHandler(&OtelTestHandler{t}))
+	require.NoError(t, err, "Must not error without any pipelines")
+	defer l.Shutdown()
+
+	assert.NotNil(t, l.TracerProvider(), "Must return a no-op tracer provider")
+	assert.NotNil(t, l.MeterProvider(), "Must return a no-op meter provider")
+	assert.NotNil(t, l.LoggerProvider(), "Must return a no-op logger provider")
+	assert.Empty(t, l.Propagator().Fields(), "Must return an empty propagator")
+}
+
+func TestLauncherSpanProcessors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	testCases := []struct {
+		scenario string
+		opts     []config.TracingOption
+		spans    int
+	}{
+		{
+			scenario: "Synchronous export",
+			opts:     []config.TracingOption{config.WithTracingSynchronousExport()},
+			spans:    1,
+		},
+		{
+			scenario: "Full batch export",
+			opts: []config.TracingOption{
+				config.WithTracingBatchTimeout(time.Hour),
+				config.WithTracingBatchMaxQueueSize(10),
+				config.WithTracingBatchMaxExportBatchSize(2),
+				config.WithTracingBatchBlockOnQueueFull(),
+			},
+			spans: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.scenario, func(t *testing.T) {
+			spans := &orderedExporter{}
+			name := exporterName("launcher-test-processor")
+			require.NoError(t, exporters.RegisterTrace(name, func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+				return spans, nil
+			}))
+
+			l, err := launcher.New(ctx,
+				config.WithoutGlobals(),
+				config.WithOtelErrorHandler(&OtelTestHandler{t}),
+				config.WithTracesPipeline(append(tc.opts,
+					config.WithTracingExporterOptions(config.WithExporterNamed(name)),
+				)...),
+			)
+			require.NoError(t, err, "Must not error when starting the launcher")
+			defer l.Shutdown()
+
+			for i := 0; i < tc.spans; i++ {
+				_, span := l.TracerProvider().Tracer("test").Start(ctx, "processed")
+				span.End()
+			}
+			assert.Eventually(t, func() bool {
+				return len(spans.Calls()) == 1
+			}, time.Second, 10*time.Millisecond, "Must export without waiting for the batch timeout")
+		})
+	}
+}
+
+func TestLauncherSpanLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inmemory := tracetest.NewInMemoryExporter()
+	name := exporterName("launcher-test-limits")
+	require.NoError(t, exporters.RegisterTrace(name, func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+		return inmemory, nil
+	}))
+
+	l, err := launcher.New(ctx,
+		config.WithoutGlobals(),
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithTracesPipeline(
+			config.WithTracingSynchronousExport(),
+			config.WithTracingEventCountLimit(2),
+			config.WithTracingAttributeValueLengthLimit(8),
+			config.WithTracingExporterOptions(config.WithExporterNamed(name)),
+		),
+	)
+	require.NoError(t, err, "Must not error when starting the launcher")
+	defer l.Shutdown()
+
+	_, span := l.TracerProvider().Tracer("test").Start(ctx, "limited")
+	span.SetAttributes(attribute.String("query", "SELECT * FROM orders"))
+	for i := 0; i < 5; i++ {
+		span.AddEvent("retry")
+	}
+	span.End()
+
+	spans := inmemory.GetSpans()
+	require.Len(t, spans, 1, "Must have exported the span")
+	assert.Len(t, spans[0].Events, 2, "Must limit the number of events")
+	assert.Equal(t, 3, spans[0].DroppedEvents)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("query", "SELECT *")}, spans[0].Attributes)
+}
+
+func TestLauncherResourceDetectors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inmemory := tracetest.NewInMemoryExporter()
+	name := exporterName("launcher-test-detectors")
+	require.NoError(t, exporters.RegisterTrace(name, func(_ context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+		return inmemory, nil
+	}))
+
+	l, err := launcher.New(ctx,
+		config.WithoutGlobals(),
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithResourceDetectors(detectors.Host, detectors.ServiceInstance),
+		config.WithAttributes(attribute.String("host.name", "configured")),
+		config.WithTracesPipeline(
+			config.WithTracingSynchronousExport(),
+			config.WithTracingExporterOptions(config.WithExporterNamed(name)),
+		),
+	)
+	require.NoError(t, err, "Must not error when starting the launcher")
+	defer l.Shutdown()
+
+	_, span := l.TracerProvider().Tracer("test").Start(ctx, "detected")
+	span.End()
+
+	spans := inmemory.GetSpans()
+	require.Len(t, spans, 1, "Must have exported the span")
+	attrs := spans[0].Resource.Set()
+	host, _ := attrs.Value("host.name")
+	assert.Equal(t, "configured", host.AsString(), "Must prefer the configured attributes over detected attributes")
+	_, ok := attrs.Value("service.instance.id")
+	assert.True(t, ok, "Must include the detected attributes")
+}
+
+func TestLauncherReportsResourceConflicts(t *testing.T) {
+	var handled []error
+	l, err := launcher.New(context.Background(),
+		config.WithoutGlobals(),
+		config.WithOtelErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			handled = append(handled, err)
+		})),
+		config.WithResourceConflictReporting(),
+		config.WithAttributes(attribute.String("host.name", "configured")),
+		config.WithResourceDetectors(detectors.Host),
+	)
+	require.NoError(t, err, "Must not fail to start because of resource conflicts")
+	defer l.Shutdown()
+
+	if assert.Len(t, handled, 1, "Must report the conflicting host name") {
+		assert.ErrorIs(t, handled[0], config.ErrResourceConflict)
+		assert.Contains(t, handled[0].Error(), `host.name set to "configured" by WithAttributes`)
+	}
+}
+
+func TestLauncherOnlyReportsDetectorConflictsByDefault(t *testing.T) {
+	var handled []error
+	l, err := launcher.New(context.Background(),
+		config.WithoutGlobals(),
+		config.WithOtelErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			handled = append(handled, err)
+		})),
+		config.WithAttributes(attribute.String("host.name", "configured")),
+		config.WithResourceDetectors(detectors.Host),
+	)
+	require.NoError(t, err)
+	defer l.Shutdown()
+
+	assert.Empty(t, handled, "Must not report attributes overridden by options")
+}
+
+// shutdownLogExporter records whether it has been shutdown
+type shutdownLogExporter struct {
+	mu       sync.Mutex
+	shutdown bool
+}
+
+func (se *shutdownLogExporter) Export(context.Context, []logs.Record) error {
+	return nil
+}
+
+func (se *shutdownLogExporter) Shutdown(context.Context) error {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	se.shutdown = true
+	return nil
+}
+
+func (se *shutdownLogExporter) isShutdown() bool {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	return se.shutdown
+}
+
+// recordingLogExporter keeps the records exported to it
+type recordingLogExporter struct {
+	mu      sync.Mutex
+	records []logs.Record
+}
+
+func (re *recordingLogExporter) Export(_ context.Context, records []logs.Record) error {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	re.records = append(re.records, records...)
+	return nil
+}
+
+func (re *recordingLogExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (re *recordingLogExporter) Records() []logs.Record {
+	re.mu.Lock()
+	defer re.mu.Unlock()
+
+	return append([]logs.Record(nil), re.records...)
+}
+
+func TestLauncherLogs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recorder := &recordingLogExporter{}
+	name := exporterName("launcher-test-logs")
+	require.NoError(t, exporters.RegisterLog(name, func(_ context.Context, _ *config.Export) (logs.Exporter, error) {
+		return recorder, nil
+	}))
+
+	l, err := launcher.New(ctx,
+		config.WithoutGlobals(),
+		config.WithOtelErrorHandler(&OtelTestHandler{t}),
+		config.WithServiceName("checkout"),
+		config.WithTracesPipeline(
+			config.WithTracingSampler(config.SamplerAlwaysOn, 0),
+			config.WithTracingExporterOptions(config.WithExporterNamed("stdout")),
+		),
+		config.WithLogsPipeline(
+			config.WithLogsExporterOptions(config.WithExporterNamed(name)),
+		),
+	)
+	require.NoError(t, err, "Must not error when starting the launcher")
+	defer l.Shutdown()
+
+	spanCtx, span := l.TracerProvider().Tracer("test").Start(ctx, "charge")
+	logs.NewStdLogger(spanCtx, l.LoggerProvider().Logger("payments"), logs.SeverityWarn).Print("card declined")
+	span.End()
+
+	require.NoError(t, l.ForceFlush(ctx), "Must flush the logs pipeline")
+
+	records := recorder.Records()
+	require.Len(t, records, 1, "Must have exported the record")
+	assert.Equal(t, "card declined", records[0].Body)
+	assert.Equal(t, logs.SeverityWarn, records[0].Severity)
+	assert.Equal(t, "payments", records[0].Scope)
+	assert.Equal(t, span.SpanContext().TraceID(), records[0].TraceID, "Must correlate the record with the active trace")
+	assert.Equal(t, span.SpanContext().SpanID(), records[0].SpanID, "Must correlate the record with the active span")
+	service, _ := records[0].Resource.Set().Value("service.name")
+	assert.Equal(t, "checkout", service.AsString(), "Must share the resource with the other pipelines")
+}
+
+func TestLauncherLogsExporterError(t *testing.T) {
+	_, err := launcher.New(context.Background(),
+		config.WithoutGlobals(),
+		config.WithLogsPipeline(config.WithLogsExporterOptions(config.WithExporterNamed("file"))),
+	)
+
+	var pe *launcher.PipelineError
+	require.ErrorAs(t, err, &pe, "Must return a pipeline error")
+	assert.Equal(t, launcher.PipelineLogs, pe.Pipeline)
+	assert.Equal(t, launcher.StageExporter, pe.Stage)
+}
@@ -0,0 +1,42 @@
+package logs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type writer struct {
+	ctx      context.Context
+	logger   Logger
+	severity Severity
+	attrs    []attribute.KeyValue
+}
+
+// NewWriter returns a writer that emits each write as a record using the logger,
+// the records carry the trace and span IDs found within ctx.
+// Each write is a single record so that multi-line messages are not split,
+// which matches how the standard library log.Logger writes each message.
+func NewWriter(ctx context.Context, logger Logger, severity Severity, attrs ...attribute.KeyValue) io.Writer {
+	return &writer{ctx: ctx, logger: logger, severity: severity, attrs: attrs}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	body := bytes.TrimSuffix(p, []byte("\n"))
+	if len(body) != 0 {
+		w.logger.Emit(w.ctx, w.severity, string(body), w.attrs...)
+	}
+	return len(p), nil
+}
+
+// NewStdLogger returns a standard library logger that emits its output using the logger,
+// create one within each request's context to correlate the output with the active span, ie:
+//
+//	logger := logs.NewStdLogger(r.Context(), provider.Logger("checkout"), logs.SeverityInfo)
+//	logger.Printf("charged %d cents", amount)
+func NewStdLogger(ctx context.Context, logger Logger, severity Severity, attrs ...attribute.KeyValue) *log.Logger {
+	return log.New(NewWriter(ctx, logger, severity, attrs...), "", 0)
+}
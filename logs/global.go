@@ -0,0 +1,44 @@
+package logs
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type providerHolder struct {
+	provider LoggerProvider
+}
+
+var global atomic.Value
+
+// SetLoggerProvider registers the provider used by the loggers from GetLoggerProvider
+func SetLoggerProvider(provider LoggerProvider) {
+	global.Store(providerHolder{provider: provider})
+}
+
+// GetLoggerProvider returns a provider whose loggers forward their records
+// to the provider registered by SetLoggerProvider, records are dropped
+// until a provider is registered
+func GetLoggerProvider() LoggerProvider {
+	return delegateProvider{}
+}
+
+// delegateProvider looks up the registered provider on each record
+// so that loggers created during package initialisation are not left as no-ops
+type delegateProvider struct{}
+
+type delegateLogger struct {
+	name string
+}
+
+func (delegateProvider) Logger(name string) Logger {
+	return delegateLogger{name: name}
+}
+
+func (d delegateLogger) Emit(ctx context.Context, severity Severity, body string, attrs ...attribute.KeyValue) {
+	if h, ok := global.Load().(providerHolder); ok {
+		h.provider.Logger(d.name).Emit(ctx, severity, body, attrs...)
+	}
+}
@@ -0,0 +1,124 @@
+// Package logs defines the log records sent by the logs pipeline
+// and bridges existing loggers so that their output carries the
+// trace and span IDs of the context it was written within.
+package logs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Severity is the severity number defined by the log data model,
+// values between the named severities are more specific levels, ie SeverityInfo+1 is INFO2
+type Severity int32
+
+const (
+	SeverityUndefined Severity = 0
+	SeverityTrace     Severity = 1
+	SeverityDebug     Severity = 5
+	SeverityInfo      Severity = 9
+	SeverityWarn      Severity = 13
+	SeverityError     Severity = 17
+	SeverityFatal     Severity = 21
+)
+
+var severityNames = []struct {
+	severity Severity
+	name     string
+}{
+	{SeverityFatal, "FATAL"},
+	{SeverityError, "ERROR"},
+	{SeverityWarn, "WARN"},
+	{SeverityInfo, "INFO"},
+	{SeverityDebug, "DEBUG"},
+	{SeverityTrace, "TRACE"},
+}
+
+// String returns the short name of the severity, ie INFO or ERROR3
+func (s Severity) String() string {
+	if s < SeverityTrace || s > SeverityFatal+3 {
+		return ""
+	}
+	for _, sn := range severityNames {
+		if s >= sn.severity {
+			if s == sn.severity {
+				return sn.name
+			}
+			return fmt.Sprintf("%s%d", sn.name, s-sn.severity+1)
+		}
+	}
+	return ""
+}
+
+// Record is a single log entry
+type Record struct {
+	Timestamp  time.Time
+	Severity   Severity
+	Body       string
+	Attributes []attribute.KeyValue
+
+	// TraceID, SpanID and TraceFlags are taken from
+	// the span context active when the record was emitted
+	TraceID    trace.TraceID
+	SpanID     trace.SpanID
+	TraceFlags trace.TraceFlags
+
+	// Scope is the name of the Logger that emitted the record
+	Scope string
+	// Resource describes the service that emitted the record
+	Resource *resource.Resource
+}
+
+// NewRecord creates a record for the message using
+// the span context found within ctx
+func NewRecord(ctx context.Context, severity Severity, body string, attrs ...attribute.KeyValue) Record {
+	r := Record{
+		Timestamp:  time.Now(),
+		Severity:   severity,
+		Body:       body,
+		Attributes: append([]attribute.KeyValue(nil), attrs...),
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.TraceID, r.SpanID, r.TraceFlags = sc.TraceID(), sc.SpanID(), sc.TraceFlags()
+	}
+	return r
+}
+
+// Logger emits log records
+type Logger interface {
+	// Emit records the message with the trace and span IDs found within ctx
+	Emit(ctx context.Context, severity Severity, body string, attrs ...attribute.KeyValue)
+}
+
+// LoggerProvider creates named loggers,
+// the name is used as the instrumentation scope of the records
+type LoggerProvider interface {
+	Logger(name string) Logger
+}
+
+// Exporter sends batches of records to a backend,
+// it is used by the exporters registered with the exporters package
+type Exporter interface {
+	Export(ctx context.Context, records []Record) error
+	Shutdown(ctx context.Context) error
+}
+
+type noopProvider struct{}
+
+type noopLogger struct{}
+
+// NewNoopLoggerProvider returns a provider whose loggers discard every record
+func NewNoopLoggerProvider() LoggerProvider {
+	return noopProvider{}
+}
+
+func (noopProvider) Logger(string) Logger {
+	return noopLogger{}
+}
+
+func (noopLogger) Emit(context.Context, Severity, string, ...attribute.KeyValue) {}
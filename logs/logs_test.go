@@ -0,0 +1,118 @@
+package logs_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// recordingLogger keeps the records emitted to it
+type recordingLogger struct {
+	mu      sync.Mutex
+	records []logs.Record
+}
+
+func (rl *recordingLogger) Logger(string) logs.Logger {
+	return rl
+}
+
+func (rl *recordingLogger) Emit(ctx context.Context, severity logs.Severity, body string, attrs ...attribute.KeyValue) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.records = append(rl.records, logs.NewRecord(ctx, severity, body, attrs...))
+}
+
+func spanContext(t *testing.T) context.Context {
+	t.Helper()
+
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func TestSeverityString(t *testing.T) {
+	t.Parallel()
+
+	for severity, expect := range map[logs.Severity]string{
+		logs.SeverityUndefined: "",
+		logs.SeverityTrace:     "TRACE",
+		logs.SeverityDebug + 1: "DEBUG2",
+		logs.SeverityInfo:      "INFO",
+		logs.SeverityWarn:      "WARN",
+		logs.SeverityError + 3: "ERROR4",
+		logs.SeverityFatal:     "FATAL",
+		logs.SeverityFatal + 4: "",
+	} {
+		assert.Equal(t, expect, severity.String(), "Must match the short name of severity %d", severity)
+	}
+}
+
+func TestNewRecordUsesSpanContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := spanContext(t)
+	r := logs.NewRecord(ctx, logs.SeverityInfo, "charged card", attribute.Int("cents", 1250))
+
+	sc := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, sc.TraceID(), r.TraceID)
+	assert.Equal(t, sc.SpanID(), r.SpanID)
+	assert.Equal(t, trace.FlagsSampled, r.TraceFlags)
+	assert.Equal(t, []attribute.KeyValue{attribute.Int("cents", 1250)}, r.Attributes)
+	assert.False(t, r.Timestamp.IsZero(), "Must set the timestamp")
+
+	r = logs.NewRecord(context.Background(), logs.SeverityInfo, "no span")
+	assert.False(t, r.TraceID.IsValid(), "Must not set a trace ID without a span")
+}
+
+func TestStdLoggerBridge(t *testing.T) {
+	t.Parallel()
+
+	var (
+		recorder = &recordingLogger{}
+		ctx      = spanContext(t)
+	)
+	logger := logs.NewStdLogger(ctx, recorder, logs.SeverityWarn, attribute.String("component", "payments"))
+	logger.Printf("card declined %d times", 3)
+	logger.Print("first line\nsecond line")
+
+	require.Len(t, recorder.records, 2, "Must emit a record for each message")
+	assert.Equal(t, "card declined 3 times", recorder.records[0].Body)
+	assert.Equal(t, "first line\nsecond line", recorder.records[1].Body, "Must not split multi-line messages")
+	for _, r := range recorder.records {
+		assert.Equal(t, logs.SeverityWarn, r.Severity)
+		assert.Equal(t, trace.SpanContextFromContext(ctx).TraceID(), r.TraceID)
+		assert.Equal(t, []attribute.KeyValue{attribute.String("component", "payments")}, r.Attributes)
+	}
+}
+
+func TestGlobalLoggerProvider(t *testing.T) {
+	logger := logs.GetLoggerProvider().Logger("early")
+	assert.NotPanics(t, func() {
+		logger.Emit(context.Background(), logs.SeverityInfo, "dropped")
+	}, "Must allow records before a provider is set")
+
+	recorder := &recordingLogger{}
+	logs.SetLoggerProvider(recorder)
+	t.Cleanup(func() {
+		logs.SetLoggerProvider(logs.NewNoopLoggerProvider())
+	})
+
+	logger.Emit(context.Background(), logs.SeverityInfo, "forwarded")
+	if assert.Len(t, recorder.records, 1, "Must forward records from loggers created before the provider was set") {
+		assert.Equal(t, "forwarded", recorder.records[0].Body)
+	}
+}
@@ -0,0 +1,369 @@
+// Package collector provides a fake OTLP collector for integration tests.
+// It accepts traces and metrics using OTLP/gRPC and OTLP/HTTP, with either
+// protobuf or JSON bodies, and records each request along with its headers
+// and compression so tests can check what the exporters sent.
+//
+//	c := collector.New(t)
+//	l := otelstartertest.New(t, ...) // or a launcher exporting to c.GRPCEndpoint()
+//	spans, err := c.WaitForSpans(ctx, 1)
+package collector
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // Allows the exporters to send compressed requests
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/MovieStoreGuy/otel-go-starter/internal/otlpjson"
+)
+
+// Signal is the type of telemetry sent in a request
+type Signal string
+
+const (
+	SignalTraces  Signal = "traces"
+	SignalMetrics Signal = "metrics"
+)
+
+// Protocol is the transport a request was received on
+type Protocol string
+
+const (
+	ProtocolGRPC Protocol = "grpc"
+	ProtocolHTTP Protocol = "http"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// Request is a decoded export request received by the collector
+type Request struct {
+	Protocol Protocol
+	Signal   Signal
+	// Headers are the HTTP headers or gRPC metadata sent with the request
+	Headers http.Header
+	// Compression is the encoding of the request body, empty when uncompressed
+	Compression string
+	// ContentType is the content type of an OTLP/HTTP request
+	ContentType string
+	// Path is the URL path of an OTLP/HTTP request
+	Path string
+
+	// Traces is set when the request's signal is SignalTraces
+	Traces *coltracepb.ExportTraceServiceRequest
+	// Metrics is set when the request's signal is SignalMetrics
+	Metrics *colmetricspb.ExportMetricsServiceRequest
+}
+
+// Collector records the requests sent to its OTLP/gRPC and OTLP/HTTP endpoints
+type Collector struct {
+	grpc     *grpc.Server
+	grpcAddr string
+	http     *httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	// received is closed and replaced each time a request is recorded
+	received chan struct{}
+}
+
+// New starts a collector listening on loopback addresses,
+// the collector is stopped once the test completes
+func New(t testing.TB) *Collector {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen for OTLP/gRPC: %v", err)
+	}
+
+	c := &Collector{
+		grpc:     grpc.NewServer(grpc.StatsHandler(compressionHandler{})),
+		grpcAddr: lis.Addr().String(),
+		received: make(chan struct{}),
+	}
+	coltracepb.RegisterTraceServiceServer(c.grpc, &traceService{c: c})
+	colmetricspb.RegisterMetricsServiceServer(c.grpc, &metricsService{c: c})
+	go func() { _ = c.grpc.Serve(lis) }()
+
+	// Any path ending with the signal is accepted so that
+	// exporters using a prefix or a custom path can be tested
+	var (
+		traces  = c.handleHTTP(SignalTraces)
+		metrics = c.handleHTTP(SignalMetrics)
+	)
+	c.http = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/traces"):
+			traces(rw, r)
+		case strings.HasSuffix(r.URL.Path, "/metrics"):
+			metrics(rw, r)
+		default:
+			http.NotFound(rw, r)
+		}
+	}))
+
+	t.Cleanup(func() {
+		c.grpc.Stop()
+		c.http.Close()
+	})
+	return c
+}
+
+// GRPCEndpoint returns the insecure endpoint accepting OTLP/gRPC
+func (c *Collector) GRPCEndpoint() string {
+	return "http://" + c.grpcAddr
+}
+
+// HTTPEndpoint returns the base URL accepting OTLP/HTTP, requests are accepted
+// at /v1/traces and /v1/metrics or any other path ending with the signal
+func (c *Collector) HTTPEndpoint() string {
+	return c.http.URL
+}
+
+// Requests returns the requests received in the order they arrived
+func (c *Collector) Requests() []Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Request(nil), c.requests...)
+}
+
+// Spans returns every span received
+func (c *Collector) Spans() []*tracepb.Span {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.spans()
+}
+
+// Metrics returns every metric received, a metric
+// is returned once for each request it was sent in
+func (c *Collector) Metrics() []*metricspb.Metric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var metrics []*metricspb.Metric
+	for _, r := range c.requests {
+		if r.Metrics == nil {
+			continue
+		}
+		for _, rm := range r.Metrics.ResourceMetrics {
+			for _, ilm := range rm.InstrumentationLibraryMetrics {
+				metrics = append(metrics, ilm.Metrics...)
+			}
+		}
+	}
+	return metrics
+}
+
+// WaitForSpans blocks until at least n spans have been received and returns them,
+// an error is returned if ctx is done before then
+func (c *Collector) WaitForSpans(ctx context.Context, n int) ([]*tracepb.Span, error) {
+	for {
+		c.mu.Lock()
+		spans, received := c.spans(), c.received
+		c.mu.Unlock()
+
+		if len(spans) >= n {
+			return spans, nil
+		}
+		select {
+		case <-received:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("received %d of %d spans: %w", len(spans), n, ctx.Err())
+		}
+	}
+}
+
+func (c *Collector) spans() []*tracepb.Span {
+	var spans []*tracepb.Span
+	for _, r := range c.requests {
+		if r.Traces == nil {
+			continue
+		}
+		for _, rs := range r.Traces.ResourceSpans {
+			for _, ils := range rs.InstrumentationLibrarySpans {
+				spans = append(spans, ils.Spans...)
+			}
+		}
+	}
+	return spans
+}
+
+func (c *Collector) record(r Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.requests, r)
+	close(c.received)
+	c.received = make(chan struct{})
+}
+
+func (c *Collector) handleHTTP(signal Signal) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, "only POST is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var body io.Reader = r.Body
+		switch encoding := r.Header.Get("Content-Encoding"); encoding {
+		case "":
+		case "gzip":
+			gr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			defer gr.Close()
+			body = gr
+		default:
+			http.Error(rw, "unsupported encoding "+encoding, http.StatusUnsupportedMediaType)
+			return
+		}
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var (
+			contentType = r.Header.Get("Content-Type")
+			unmarshal   func([]byte, proto.Message) error
+			marshal     func(proto.Message) ([]byte, error)
+		)
+		switch {
+		case strings.HasPrefix(contentType, contentTypeProtobuf):
+			unmarshal, marshal = proto.Unmarshal, proto.Marshal
+		case strings.HasPrefix(contentType, contentTypeJSON):
+			unmarshal, marshal = otlpjson.Unmarshal, otlpjson.Marshal
+		default:
+			http.Error(rw, "unsupported content type "+contentType, http.StatusUnsupportedMediaType)
+			return
+		}
+
+		req := Request{
+			Protocol:    ProtocolHTTP,
+			Signal:      signal,
+			Headers:     r.Header.Clone(),
+			Compression: r.Header.Get("Content-Encoding"),
+			ContentType: contentType,
+			Path:        r.URL.Path,
+		}
+		var (
+			msg  proto.Message
+			resp proto.Message
+		)
+		switch signal {
+		case SignalTraces:
+			req.Traces = &coltracepb.ExportTraceServiceRequest{}
+			msg, resp = req.Traces, &coltracepb.ExportTraceServiceResponse{}
+		case SignalMetrics:
+			req.Metrics = &colmetricspb.ExportMetricsServiceRequest{}
+			msg, resp = req.Metrics, &colmetricspb.ExportMetricsServiceResponse{}
+		}
+		if err := unmarshal(raw, msg); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.record(req)
+
+		b, err := marshal(resp)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", contentType)
+		_, _ = rw.Write(b)
+	}
+}
+
+// grpcRequest creates the request from the incoming gRPC metadata
+func grpcRequest(ctx context.Context, signal Signal) Request {
+	req := Request{
+		Protocol: ProtocolGRPC,
+		Signal:   signal,
+		Headers:  http.Header{},
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for k, vs := range md {
+		for _, v := range vs {
+			req.Headers.Add(k, v)
+		}
+	}
+	if compression, ok := ctx.Value(compressionKey{}).(*string); ok {
+		req.Compression = *compression
+	}
+	return req
+}
+
+type traceService struct {
+	coltracepb.UnimplementedTraceServiceServer
+
+	c *Collector
+}
+
+func (ts *traceService) Export(ctx context.Context, r *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	req := grpcRequest(ctx, SignalTraces)
+	req.Traces = r
+	ts.c.record(req)
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+type metricsService struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+
+	c *Collector
+}
+
+func (ms *metricsService) Export(ctx context.Context, r *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	req := grpcRequest(ctx, SignalMetrics)
+	req.Metrics = r
+	ms.c.record(req)
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+type compressionKey struct{}
+
+// compressionHandler stores the compression of each RPC in its context,
+// the compression is not included in the incoming metadata
+type compressionHandler struct{}
+
+var _ stats.Handler = compressionHandler{}
+
+func (compressionHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return context.WithValue(ctx, compressionKey{}, new(string))
+}
+
+func (compressionHandler) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if in, ok := s.(*stats.InHeader); ok {
+		if compression, ok := ctx.Value(compressionKey{}).(*string); ok {
+			*compression = in.Compression
+		}
+	}
+}
+
+func (compressionHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (compressionHandler) HandleConn(context.Context, stats.ConnStats) {}
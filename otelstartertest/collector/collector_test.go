@@ -0,0 +1,103 @@
+package collector_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MovieStoreGuy/otel-go-starter/otelstartertest/collector"
+)
+
+const jsonTraces = `{"resourceSpans":[{"instrumentationLibrarySpans":[{"spans":[{
+	"traceId":"4bf92f3577b34da6a3ce929d0e0e4736",
+	"spanId":"00f067aa0ba902b7",
+	"name":"checkout"
+}]}]}]}`
+
+func TestHTTPJSON(t *testing.T) {
+	t.Parallel()
+
+	c := collector.New(t)
+
+	req, err := http.NewRequest(http.MethodPost, c.HTTPEndpoint()+"/v1/traces", bytes.NewBufferString(jsonTraces))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Api-Key", "secret")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"), "Must respond using the request's content type")
+
+	requests := c.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, collector.ProtocolHTTP, requests[0].Protocol)
+	assert.Equal(t, collector.SignalTraces, requests[0].Signal)
+	assert.Equal(t, "secret", requests[0].Headers.Get("Api-Key"))
+	assert.Equal(t, "/v1/traces", requests[0].Path)
+	assert.Empty(t, requests[0].Compression)
+
+	spans := c.Spans()
+	require.Len(t, spans, 1, "Must decode the OTLP/JSON body")
+	assert.Equal(t, "checkout", spans[0].Name)
+	assert.Equal(t, []byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}, spans[0].SpanId)
+}
+
+func TestHTTPRejected(t *testing.T) {
+	t.Parallel()
+
+	c := collector.New(t)
+
+	testCases := []struct {
+		scenario    string
+		path        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{scenario: "Unknown signal", path: "/v1/profiles", contentType: "application/json", body: "{}", status: http.StatusNotFound},
+		{scenario: "Unsupported content type", path: "/v1/traces", contentType: "text/plain", body: "{}", status: http.StatusUnsupportedMediaType},
+		{scenario: "Invalid body", path: "/v1/metrics", contentType: "application/json", body: "[", status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		resp, err := http.Post(c.HTTPEndpoint()+tc.path, tc.contentType, bytes.NewBufferString(tc.body))
+		require.NoError(t, err, tc.scenario)
+		resp.Body.Close()
+		assert.Equal(t, tc.status, resp.StatusCode, tc.scenario)
+	}
+	assert.Empty(t, c.Requests(), "Must not record rejected requests")
+}
+
+func TestWaitForSpans(t *testing.T) {
+	t.Parallel()
+
+	c := collector.New(t)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		resp, err := http.Post(c.HTTPEndpoint()+"/v1/traces", "application/json", bytes.NewBufferString(jsonTraces))
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+		}
+	}()
+
+	ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+	defer done()
+
+	spans, err := c.WaitForSpans(ctx, 1)
+	require.NoError(t, err, "Must return once the spans are received")
+	assert.Len(t, spans, 1)
+
+	ctx, done = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer done()
+
+	_, err = c.WaitForSpans(ctx, 2)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "Must stop waiting once the context is done")
+}
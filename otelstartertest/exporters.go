@@ -0,0 +1,165 @@
+package otelstartertest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/exporters"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+// ExporterName is the name the in-memory exporters are registered under,
+// the exporters are looked up from the context passed to the launcher
+// so that each test has its own exporters
+const ExporterName = "otelstartertest"
+
+// ErrNoTestLauncher is returned when the in-memory exporters
+// are selected by a launcher that was not started by New
+var ErrNoTestLauncher = errors.New("exporter used outside of otelstartertest.New")
+
+type launcherKey struct{}
+
+var register sync.Once
+
+// registerExporters adds the in-memory exporters to the factories,
+// registration only fails when the name is already in use
+func registerExporters() (err error) {
+	register.Do(func() {
+		if err = exporters.RegisterTrace(ExporterName, func(ctx context.Context, _ *config.Export) (sdktrace.SpanExporter, error) {
+			l, err := fromContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return l.spans, nil
+		}); err != nil {
+			return
+		}
+		if err = exporters.RegisterMetric(ExporterName, func(ctx context.Context, _ *config.Export) (sdkmetric.Exporter, error) {
+			l, err := fromContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return l.metrics, nil
+		}); err != nil {
+			return
+		}
+		err = exporters.RegisterLog(ExporterName, func(ctx context.Context, _ *config.Export) (logs.Exporter, error) {
+			l, err := fromContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return l.records, nil
+		})
+	})
+	return err
+}
+
+func fromContext(ctx context.Context) (*Launcher, error) {
+	l, ok := ctx.Value(launcherKey{}).(*Launcher)
+	if !ok {
+		return nil, ErrNoTestLauncher
+	}
+	return l, nil
+}
+
+// metricExporter keeps the latest cumulative value of each metric and attribute set
+type metricExporter struct {
+	mu     sync.Mutex
+	values map[string]map[attribute.Distinct]float64
+}
+
+var _ sdkmetric.Exporter = (*metricExporter)(nil)
+
+func newMetricExporter() *metricExporter {
+	return &metricExporter{values: make(map[string]map[attribute.Distinct]float64)}
+}
+
+func (me *metricExporter) Export(_ context.Context, _ *resource.Resource, reader sdkmetric.InstrumentationLibraryReader) error {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	return reader.ForEach(func(_ instrumentation.Library, r sdkmetric.Reader) error {
+		return r.ForEach(me, func(rec sdkmetric.Record) error {
+			desc := rec.Descriptor()
+			var value float64
+			switch agg := rec.Aggregation().(type) {
+			case aggregation.Sum:
+				sum, err := agg.Sum()
+				if err != nil {
+					return err
+				}
+				value = sum.CoerceToFloat64(desc.NumberKind())
+			case aggregation.LastValue:
+				last, _, err := agg.LastValue()
+				if err != nil {
+					return err
+				}
+				value = last.CoerceToFloat64(desc.NumberKind())
+			default:
+				return nil
+			}
+			if me.values[desc.Name()] == nil {
+				me.values[desc.Name()] = make(map[attribute.Distinct]float64)
+			}
+			me.values[desc.Name()][rec.Labels().Equivalent()] = value
+			return nil
+		})
+	})
+}
+
+func (me *metricExporter) ExportKindFor(_ *otelmetric.Descriptor, _ aggregation.Kind) sdkmetric.ExportKind {
+	return sdkmetric.CumulativeExportKind
+}
+
+func (me *metricExporter) value(name string, attrs []attribute.KeyValue) (float64, bool) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	set := attribute.NewSet(attrs...)
+	v, ok := me.values[name][set.Equivalent()]
+	return v, ok
+}
+
+// logExporter keeps every record exported to it
+type logExporter struct {
+	mu      sync.Mutex
+	records []logs.Record
+}
+
+var _ logs.Exporter = (*logExporter)(nil)
+
+func (le *logExporter) Export(_ context.Context, records []logs.Record) error {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	le.records = append(le.records, records...)
+	return nil
+}
+
+func (le *logExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func (le *logExporter) get() []logs.Record {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	return append([]logs.Record(nil), le.records...)
+}
+
+func (le *logExporter) reset() {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+
+	le.records = nil
+}
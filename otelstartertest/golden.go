@@ -0,0 +1,107 @@
+package otelstartertest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EnvUpdateGolden rewrites the golden files when set to true,
+// an environment variable is used rather than a flag so that
+// importing the package does not add flags to the binary
+const EnvUpdateGolden = "OTELSTARTERTEST_UPDATE"
+
+// AssertGoldenTrace compares the shape of the ended spans, as rendered by FormatTraces,
+// with the golden file at path. Running the tests with OTELSTARTERTEST_UPDATE=true
+// writes the current shape to the file instead.
+func (l *Launcher) AssertGoldenTrace(path string) bool {
+	l.t.Helper()
+
+	actual := FormatTraces(l.Spans())
+	if os.Getenv(EnvUpdateGolden) == "true" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return assert.Fail(l.t, "Unable to create the golden file directory", err.Error())
+		}
+		if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+			return assert.Fail(l.t, "Unable to write the golden file", err.Error())
+		}
+		l.t.Logf("Updated golden file %s", path)
+		return true
+	}
+
+	expect, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return assert.Fail(l.t, fmt.Sprintf("Golden file %s does not exist", path), "Run the tests with "+EnvUpdateGolden+"=true to create it")
+	}
+	if err != nil {
+		return assert.Fail(l.t, "Unable to read the golden file", err.Error())
+	}
+	return assert.Equal(l.t, string(expect), actual, "Traces do not match %s, run the tests with %s=true to accept the changes", path, EnvUpdateGolden)
+}
+
+// FormatTraces renders the spans as an indented tree of each span's name, kind
+// and sorted attribute keys, with children below their parent. The IDs, timestamps
+// and attribute values are left out so the output is the same for each run.
+// Spans whose parent was not recorded are treated as roots, and siblings
+// are ordered by their rendered line so the order they ended does not matter.
+func FormatTraces(spans tracetest.SpanStubs) string {
+	var (
+		recorded = make(map[trace.SpanID]bool, len(spans))
+		children = make(map[trace.SpanID][]tracetest.SpanStub, len(spans))
+		roots    []tracetest.SpanStub
+	)
+	for _, s := range spans {
+		recorded[s.SpanContext.SpanID()] = true
+	}
+	for _, s := range spans {
+		if parent := s.Parent.SpanID(); s.Parent.IsValid() && recorded[parent] {
+			children[parent] = append(children[parent], s)
+			continue
+		}
+		roots = append(roots, s)
+	}
+
+	var sb strings.Builder
+	var write func(spans []tracetest.SpanStub, depth int)
+	write = func(spans []tracetest.SpanStub, depth int) {
+		lines := make([]string, len(spans))
+		for i, s := range spans {
+			lines[i] = formatSpan(s)
+		}
+		order := make([]int, len(spans))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool { return lines[order[i]] < lines[order[j]] })
+
+		for _, i := range order {
+			sb.WriteString(strings.Repeat("  ", depth))
+			sb.WriteString(lines[i])
+			sb.WriteByte('\n')
+			write(children[spans[i].SpanContext.SpanID()], depth+1)
+		}
+	}
+	write(roots, 0)
+	return sb.String()
+}
+
+func formatSpan(s tracetest.SpanStub) string {
+	keys := make([]string, 0, len(s.Attributes))
+	seen := make(map[string]bool, len(s.Attributes))
+	for _, kv := range s.Attributes {
+		if k := string(kv.Key); !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return fmt.Sprintf("%s kind=%s attributes=[%s]", s.Name, s.SpanKind, strings.Join(keys, " "))
+}
@@ -0,0 +1,111 @@
+package otelstartertest_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/otelstartertest"
+)
+
+// checkout emits the trace captured by testdata/checkout.golden
+func checkout(tp oteltrace.TracerProvider, cardType string) {
+	tracer := tp.Tracer("test")
+
+	ctx, root := tracer.Start(context.Background(), "checkout",
+		oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+		oteltrace.WithAttributes(attribute.String("currency", "AUD"), attribute.Int("items", 3)),
+	)
+	_, stock := tracer.Start(ctx, "reserve stock")
+	stock.End()
+
+	ctx, charge := tracer.Start(ctx, "charge card",
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(attribute.String("card.type", cardType)),
+	)
+	_, fraud := tracer.Start(ctx, "fraud check")
+	fraud.End()
+	charge.End()
+	root.End()
+
+	_, poll := tracer.Start(context.Background(), "poll orders", oteltrace.WithSpanKind(oteltrace.SpanKindConsumer))
+	poll.End()
+}
+
+func TestAssertGoldenTrace(t *testing.T) {
+	t.Parallel()
+
+	l := otelstartertest.New(t)
+	checkout(l.TracerProvider(), "visa")
+
+	assert.True(t, l.AssertGoldenTrace(filepath.Join("testdata", "checkout.golden")))
+
+	// Values are not part of the shape of the trace
+	l.Reset()
+	checkout(l.TracerProvider(), "amex")
+	assert.True(t, l.AssertGoldenTrace(filepath.Join("testdata", "checkout.golden")))
+}
+
+func TestAssertGoldenTraceMismatch(t *testing.T) {
+	// Comparing against a copy in compare mode so that running the tests
+	// to update the golden files does not overwrite the committed file
+	t.Setenv(otelstartertest.EnvUpdateGolden, "false")
+
+	golden, err := os.ReadFile(filepath.Join("testdata", "checkout.golden"))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "checkout.golden")
+	require.NoError(t, os.WriteFile(path, golden, 0o644))
+
+	tb := &recordingTB{TB: t}
+	l := otelstartertest.New(tb)
+	_, span := l.TracerProvider().Tracer("test").Start(context.Background(), "refund")
+	span.End()
+
+	assert.False(t, l.AssertGoldenTrace(path), "Must fail when the trace differs")
+	assert.False(t, l.AssertGoldenTrace(filepath.Join(t.TempDir(), "missing.golden")), "Must fail when the golden file does not exist")
+	if assert.Len(t, tb.errors, 2) {
+		assert.Contains(t, tb.errors[0], "+refund kind=internal attributes=[]", "Must show the difference between the trees")
+	}
+}
+
+func TestAssertGoldenTraceUpdate(t *testing.T) {
+	t.Setenv(otelstartertest.EnvUpdateGolden, "true")
+
+	path := filepath.Join(t.TempDir(), "traces", "checkout.golden")
+	l := otelstartertest.New(t)
+	checkout(l.TracerProvider(), "visa")
+	require.True(t, l.AssertGoldenTrace(path), "Must write the golden file")
+
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+	expect, err := os.ReadFile(filepath.Join("testdata", "checkout.golden"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expect), string(written))
+}
+
+func TestFormatTraces(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, otelstartertest.FormatTraces(nil))
+
+	l := otelstartertest.New(t)
+	tracer := l.TracerProvider().Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	// Children ending in a different order must not change the output
+	_, b := tracer.Start(ctx, "b")
+	_, a := tracer.Start(ctx, "a", oteltrace.WithAttributes(attribute.Bool("z", true), attribute.Bool("y", true)))
+	b.End()
+	a.End()
+	parent.End()
+
+	expect := "parent kind=internal attributes=[]\n" +
+		"  a kind=internal attributes=[y z]\n" +
+		"  b kind=internal attributes=[]\n"
+	assert.Equal(t, expect, otelstartertest.FormatTraces(l.Spans()))
+}
@@ -0,0 +1,182 @@
+// Package otelstartertest starts a launcher whose pipelines export
+// to in-memory exporters so that tests can assert on the telemetry
+// produced by the code under test.
+//
+//	func TestCheckout(t *testing.T) {
+//	    l := otelstartertest.New(t)
+//
+//	    checkout(l.TracerProvider(), l.MeterProvider())
+//
+//	    l.AssertSpanAttributes("checkout", attribute.String("currency", "AUD"))
+//	    v, ok := l.MetricValue("checkout.orders", attribute.String("currency", "AUD"))
+//	    ...
+//	}
+//
+// Each launcher has its own providers and exporters and leaves the globals unchanged,
+// so tests using the package can run in parallel.
+package otelstartertest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	otelstarter "github.com/MovieStoreGuy/otel-go-starter"
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Launcher is an otelstarter.Launcher exporting to in-memory exporters
+type Launcher struct {
+	otelstarter.Launcher
+
+	t       testing.TB
+	spans   *tracetest.InMemoryExporter
+	metrics *metricExporter
+	records *logExporter
+}
+
+// New starts a launcher with the tracing, metrics and logs pipelines enabled
+// and exporting to in-memory exporters, the launcher is shutdown once the test completes.
+// The options are applied before the exporters are replaced so any exporters they
+// configure, including those from the environment, are not used.
+// Errors from the launcher fail the test.
+func New(t testing.TB, opts ...config.OptionFunc) *Launcher {
+	t.Helper()
+
+	if err := registerExporters(); err != nil {
+		t.Fatalf("Unable to register the in-memory exporters: %v", err)
+	}
+
+	l := &Launcher{
+		t:       t,
+		spans:   tracetest.NewInMemoryExporter(),
+		metrics: newMetricExporter(),
+		records: &logExporter{},
+	}
+
+	opts = append([]config.OptionFunc{
+		config.WithOtelErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			t.Errorf("Launcher reported an error: %v", err)
+		})),
+	}, opts...)
+	opts = append(opts, config.WithoutGlobals(), useInMemoryExporters)
+
+	ctx := context.WithValue(context.Background(), launcherKey{}, l)
+	launcher, err := otelstarter.New(ctx, opts...)
+	if err != nil {
+		t.Fatalf("Unable to start the launcher: %v", err)
+	}
+	l.Launcher = launcher
+
+	t.Cleanup(func() {
+		ctx, done := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer done()
+
+		if err := launcher.ShutdownContext(ctx); err != nil {
+			t.Errorf("Unable to shutdown the launcher: %v", err)
+		}
+	})
+	return l
+}
+
+// useInMemoryExporters enables every pipeline and replaces the configured exporters,
+// spans are exported as they end so they can be inspected without flushing
+func useInMemoryExporters(c *config.Config) error {
+	export := config.NewDefaultExport()
+	export.Named = ExporterName
+
+	c.Tracing.Enable, c.Tracing.Synchronous = true, true
+	c.Tracing.Export, c.Tracing.Exports = export, nil
+	c.Metrics.Enable = true
+	c.Metrics.Export, c.Metrics.Exports = export, nil
+	c.Logs.Enable = true
+	c.Logs.Export, c.Logs.Exports = export, nil
+	return nil
+}
+
+// Spans returns the spans that have ended
+func (l *Launcher) Spans() tracetest.SpanStubs {
+	return l.spans.GetSpans()
+}
+
+// FindSpan returns the first ended span with the given name
+func (l *Launcher) FindSpan(name string) (tracetest.SpanStub, bool) {
+	for _, s := range l.Spans() {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return tracetest.SpanStub{}, false
+}
+
+// AssertSpanAttributes checks that the first span with the given name
+// has each of the attributes, other attributes on the span are ignored
+func (l *Launcher) AssertSpanAttributes(name string, attrs ...attribute.KeyValue) bool {
+	l.t.Helper()
+
+	s, ok := l.FindSpan(name)
+	if !ok {
+		return assert.Fail(l.t, fmt.Sprintf("No span named %q", name), "Spans ended: %v", spanNames(l.Spans()))
+	}
+
+	values := make(map[attribute.Key]attribute.Value, len(s.Attributes))
+	for _, kv := range s.Attributes {
+		values[kv.Key] = kv.Value
+	}
+	ok = true
+	for _, kv := range attrs {
+		v, exist := values[kv.Key]
+		if !exist {
+			ok = assert.Fail(l.t, fmt.Sprintf("Span %q is missing attribute %q", name, kv.Key)) && ok
+			continue
+		}
+		ok = assert.Equal(l.t, kv.Value.Emit(), v.Emit(), "Span %q attribute %q", name, kv.Key) && ok
+	}
+	return ok
+}
+
+// MetricValue collects the current metrics and returns the value recorded for the
+// metric with exactly the given attributes. Counters return their cumulative sum,
+// histograms the sum of the recorded values and gauges their last value.
+func (l *Launcher) MetricValue(name string, attrs ...attribute.KeyValue) (float64, bool) {
+	l.t.Helper()
+
+	if err := l.ForceFlush(context.Background()); err != nil {
+		l.t.Errorf("Unable to collect the metrics: %v", err)
+	}
+	return l.metrics.value(name, attrs)
+}
+
+// Records flushes the logs pipeline and returns the exported log records
+func (l *Launcher) Records() []logs.Record {
+	l.t.Helper()
+
+	if err := l.ForceFlush(context.Background()); err != nil {
+		l.t.Errorf("Unable to flush the log records: %v", err)
+	}
+	return l.records.get()
+}
+
+// Reset removes the recorded spans and log records,
+// metric values are cumulative so are not reset
+func (l *Launcher) Reset() {
+	l.spans.Reset()
+	l.records.reset()
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, 0, len(spans))
+	for _, s := range spans {
+		names = append(names, s.Name)
+	}
+	return names
+}
@@ -0,0 +1,142 @@
+package otelstartertest_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/MovieStoreGuy/otel-go-starter/config"
+	"github.com/MovieStoreGuy/otel-go-starter/logs"
+	"github.com/MovieStoreGuy/otel-go-starter/otelstartertest"
+)
+
+func TestSpans(t *testing.T) {
+	t.Parallel()
+
+	l := otelstartertest.New(t, config.WithServiceName("checkout"))
+
+	tracer := l.TracerProvider().Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "checkout", oteltrace.WithAttributes(
+		attribute.String("currency", "AUD"),
+		attribute.Int("items", 3),
+	))
+	_, child := tracer.Start(ctx, "charge card")
+	child.End()
+	parent.End()
+
+	require.Len(t, l.Spans(), 2, "Must record the spans as they end")
+
+	s, ok := l.FindSpan("charge card")
+	require.True(t, ok, "Must find the span by name")
+	assert.Equal(t, parent.SpanContext().SpanID(), s.Parent.SpanID())
+	assert.Contains(t, s.Resource.Attributes(), attribute.String("service.name", "checkout"))
+
+	_, ok = l.FindSpan("refund")
+	assert.False(t, ok, "Must not find a span that was not ended")
+
+	assert.True(t, l.AssertSpanAttributes("checkout", attribute.String("currency", "AUD")))
+
+	l.Reset()
+	assert.Empty(t, l.Spans(), "Must remove the recorded spans")
+}
+
+// recordingTB keeps the errors reported by the assertions instead of failing the test
+type recordingTB struct {
+	testing.TB
+
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertSpanAttributesFailures(t *testing.T) {
+	t.Parallel()
+
+	tb := &recordingTB{TB: t}
+	l := otelstartertest.New(tb)
+	_, span := l.TracerProvider().Tracer("test").Start(context.Background(), "checkout",
+		oteltrace.WithAttributes(attribute.String("currency", "AUD")),
+	)
+	span.End()
+
+	assert.False(t, l.AssertSpanAttributes("checkout", attribute.String("currency", "NZD")), "Must fail when the value differs")
+	assert.False(t, l.AssertSpanAttributes("checkout", attribute.Int("items", 3)), "Must fail when the attribute is missing")
+	assert.False(t, l.AssertSpanAttributes("refund"), "Must fail when there is no span")
+	assert.Len(t, tb.errors, 3, "Must report each failure to the test")
+}
+
+func TestMetricValue(t *testing.T) {
+	t.Parallel()
+
+	l := otelstartertest.New(t)
+
+	meter := metric.Must(l.MeterProvider().Meter("test"))
+	orders := meter.NewInt64Counter("checkout.orders")
+	latency := meter.NewFloat64Histogram("checkout.latency")
+
+	ctx := context.Background()
+	orders.Add(ctx, 2, attribute.String("currency", "AUD"))
+	orders.Add(ctx, 1, attribute.String("currency", "AUD"))
+	orders.Add(ctx, 5, attribute.String("currency", "NZD"))
+	latency.Record(ctx, 1.5)
+	latency.Record(ctx, 2.5)
+
+	v, ok := l.MetricValue("checkout.orders", attribute.String("currency", "AUD"))
+	require.True(t, ok, "Must have a value for the attribute set")
+	assert.Equal(t, 3.0, v)
+
+	orders.Add(ctx, 4, attribute.String("currency", "AUD"))
+	v, _ = l.MetricValue("checkout.orders", attribute.String("currency", "AUD"))
+	assert.Equal(t, 7.0, v, "Must report the cumulative value")
+
+	v, ok = l.MetricValue("checkout.latency")
+	require.True(t, ok)
+	assert.Equal(t, 4.0, v, "Must report the sum of the recorded values")
+
+	_, ok = l.MetricValue("checkout.orders")
+	assert.False(t, ok, "Must only match the exact attribute set")
+	_, ok = l.MetricValue("checkout.refunds")
+	assert.False(t, ok, "Must not report metrics that were not recorded")
+}
+
+func TestRecords(t *testing.T) {
+	t.Parallel()
+
+	l := otelstartertest.New(t)
+
+	l.LoggerProvider().Logger("payments").Emit(context.Background(), logs.SeverityWarn, "card declined")
+
+	records := l.Records()
+	require.Len(t, records, 1, "Must flush the log records")
+	assert.Equal(t, "card declined", records[0].Body)
+	assert.Equal(t, "payments", records[0].Scope)
+}
+
+func TestIsolated(t *testing.T) {
+	global := otel.GetTracerProvider()
+
+	var (
+		first  = otelstartertest.New(t)
+		second = otelstartertest.New(t, config.WithServiceName("second"))
+	)
+	_, span := first.TracerProvider().Tracer("test").Start(context.Background(), "first")
+	span.End()
+
+	assert.Len(t, first.Spans(), 1)
+	assert.Empty(t, second.Spans(), "Must not share exporters between launchers")
+	assert.Equal(t, global, otel.GetTracerProvider(), "Must not change the global provider")
+}